@@ -5,14 +5,28 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
+	"time"
 
+	"music-bot-v2/internal/application/metrics"
 	"music-bot-v2/internal/application/probe"
+	"music-bot-v2/internal/application/transport"
 
+	"music-bot-v2/internal/cobalt"
+	"music-bot-v2/internal/extractor"
 	"music-bot-v2/internal/yt1s"
 
 	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/download"
+	"music-bot-v2/internal/favorites"
+	"music-bot-v2/internal/history"
+	"music-bot-v2/internal/inflight"
 	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/playlist"
+	"music-bot-v2/internal/queue"
+	"music-bot-v2/internal/ratelimit"
+	"music-bot-v2/internal/settings"
 	"music-bot-v2/internal/youtube"
 
 	"music-bot-v2/internal/application/bot"
@@ -31,20 +45,92 @@ func main() {
 
 	cacher.SetConfig(cfg.Cacher)
 
-	ytCl := youtube.NewClient(cfg.GoogleAPIKeys, nil)
-	ytExtrCl := yt1s.NewClient(nil)
+	var cacheCheckers []probe.Checker
+	newCache := func(db int, ttl time.Duration) cacher.Cache {
+		cache := cacher.New(db, ttl)
+		cacheCheckers = append(cacheCheckers, probe.NewCheck("cache_"+cacher.DBName(db), cache.Ping))
+		return cache
+	}
+
+	upstreamHTTP := transport.New(
+		transport.WithRetry(transport.DefaultRetryPolicy()),
+		transport.WithCircuitBreaker(transport.DefaultBreakerPolicy()),
+	)
+
+	ytCl := youtube.NewClient(cfg.GoogleAPIKeys, upstreamHTTP, youtube.WithDailyQuota(cfg.GoogleDailyQuota))
+	// Converters only look links up, so their POST requests are retried too.
+	extractorHTTP := transport.New(
+		transport.WithRetry(transport.DefaultRetryPolicy()),
+		transport.WithCircuitBreaker(transport.DefaultBreakerPolicy()),
+		transport.WithIdempotentRequests(),
+	)
+	extractors := []extractor.Provider{
+		{Name: "yt1s", Extractor: yt1s.NewClient(extractorHTTP)},
+	}
+	if cfg.CobaltAPIURL != "" {
+		extractors = append(extractors, extractor.Provider{
+			Name:      "cobalt",
+			Extractor: cobalt.NewClient(cfg.CobaltAPIURL, cfg.CobaltAPIKey, extractorHTTP),
+		})
+	}
+	ytExtrCl := extractor.NewChain(extractors)
+
+	ms := music.NewService(
+		newCache(cacher.SearchCacheDB, 0),
+		newCache(cacher.TokenCacheDB, 0),
+		ytCl,
+		ytExtrCl,
+		music.WithLinkCache(newCache(cacher.LinkCacheDB, 10*time.Minute)),
+	)
 
-	ms := music.NewService(ytCl, ytExtrCl)
+	playlists := playlist.NewService(newCache(cacher.PlaylistCacheDB, 0))
 
-	h := ytHandlers.NewHandler(ctx, ms)
+	handlerOptions := []ytHandlers.Option{ytHandlers.WithDownloader(download.New())}
 
-	b, err := bot.New(cfg, h.Handlers())
+	var limiterStore ratelimit.Store = ratelimit.NewMemory()
+	if cfg.Cacher.Backend != cacher.BackendMemory {
+		limiterStore = ratelimit.NewRedis(cacher.NewRedisClient(cacher.RateLimitDB))
+		// The memory backend implies a single replica, where in-process coalescing of conversions is enough.
+		conversionLock := inflight.NewRedisLock(cacher.NewRedisClient(cacher.LockDB))
+		handlerOptions = append(handlerOptions, ytHandlers.WithConversionLock(conversionLock))
+	}
+	admins := make([]string, 0, len(cfg.AdminIDs))
+	for _, id := range cfg.AdminIDs {
+		admins = append(admins, strconv.FormatInt(id, 10))
+	}
+	limiter := ratelimit.New(limiterStore, cfg.RateLimit, admins)
+
+	caches := ytHandlers.Caches{
+		Query:    newCache(cacher.QueryCacheDB, 0),
+		Panel:    newCache(cacher.PanelCacheDB, 48*time.Hour),
+		Audio:    newCache(cacher.AudioCacheDB, 0),
+		Language: newCache(cacher.LanguageDB, 0),
+	}
+	handlerOptions = append(handlerOptions,
+		ytHandlers.WithRateLimiter(limiter),
+		ytHandlers.WithQueue(queue.New(ctx, cfg.Queue)),
+		ytHandlers.WithSettings(settings.NewStore(newCache(cacher.SettingsDB, 0))),
+		ytHandlers.WithHistory(history.NewStore(newCache(cacher.HistoryDB, 0))),
+		ytHandlers.WithFavorites(favorites.NewStore(newCache(cacher.FavoritesDB, 0))),
+	)
+	h := ytHandlers.NewHandler(ctx, ms, playlists, caches, handlerOptions...)
+
+	b, err := bot.New(cfg, h.Handlers(), bot.WithCommands(h.Commands()))
 	if err != nil {
 		log.Panicln("failed to create bot: " + err.Error())
 	}
 
+	checkers := append([]probe.Checker{
+		probe.NewCheck("telegram", b.Ready),
+		// Cached searches and deliveries work without the API, and a pod out of rotation would never recover.
+		probe.Degraded(probe.NewCheck("youtube", ytCl.Ready)),
+		probe.NewCheck("link_extractors", ytExtrCl.Ready),
+		probe.NewCheck("rate_limiter", limiter.Ping),
+	}, cacheCheckers...)
+
 	mountable := []bot.Mountable{
-		probe.NewHandler(),
+		probe.NewHandler(checkers...),
+		metrics.NewHandler(metrics.Default),
 	}
 
 	if err := b.Start(ctx, mountable...); err != nil {
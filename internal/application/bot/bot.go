@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
@@ -22,15 +23,31 @@ type Mountable interface {
 	Mount(e *echo.Echo)
 }
 
+// CommandMenu publishes the bot's commands to Telegram clients.
+type CommandMenu interface {
+	SetMyCommands(ctx context.Context, b *gotgbot.Bot) error
+}
+
 type Bot struct {
 	cfg        config.Config
 	handlers   []ext.Handler
+	commands   CommandMenu
 	updater    *ext.Updater
 	dispatcher *ext.Dispatcher
 	server     *http.Server
+	client     atomic.Pointer[gotgbot.Bot]
+}
+
+type Option func(*Bot)
+
+// WithCommands publishes the command menus when the bot starts.
+func WithCommands(commands CommandMenu) Option {
+	return func(b *Bot) {
+		b.commands = commands
+	}
 }
 
-func New(cfg config.Config, handlers []ext.Handler) (*Bot, error) {
+func New(cfg config.Config, handlers []ext.Handler, options ...Option) (*Bot, error) {
 	dispatcher := ext.NewDispatcher(&ext.DispatcherOpts{
 		Processor: logger.NewUpdateProcessor(ext.BaseProcessor{}),
 		// If an error is returned by a handler, log it and continue going.
@@ -43,12 +60,16 @@ func New(cfg config.Config, handlers []ext.Handler) (*Bot, error) {
 
 	updater := ext.NewUpdater(dispatcher, &ext.UpdaterOpts{})
 
-	return &Bot{
+	b := &Bot{
 		cfg:        cfg,
 		handlers:   handlers,
 		updater:    updater,
 		dispatcher: dispatcher,
-	}, nil
+	}
+	for _, option := range options {
+		option(b)
+	}
+	return b, nil
 }
 
 func (b *Bot) Start(ctx context.Context, mountable ...Mountable) error {
@@ -60,14 +81,17 @@ func (b *Bot) Start(ctx context.Context, mountable ...Mountable) error {
 	if err != nil {
 		return err
 	}
+	b.client.Store(gtgBot)
 
 	for _, handler := range b.handlers {
 		b.dispatcher.AddHandler(handler)
 	}
 
-	webhookURL := strings.TrimSpace(b.cfg.WebhookURL)
-	if webhookURL == "" {
-		return fmt.Errorf("webhook url is empty")
+	if b.commands != nil {
+		// Menus are cosmetic, the bot works without them.
+		if err := b.commands.SetMyCommands(ctx, gtgBot); err != nil {
+			log.Printf("set bot commands err=%v", err)
+		}
 	}
 
 	listenAddr := strings.TrimSpace(b.cfg.WebhookListenAddr)
@@ -75,6 +99,52 @@ func (b *Bot) Start(ctx context.Context, mountable ...Mountable) error {
 		return fmt.Errorf("webhook listen addr is empty")
 	}
 
+	switch mode := botMode(b.cfg.BotMode); mode {
+	case config.BotModeWebhook:
+		err = b.startWebhook(gtgBot, listenAddr, mountable...)
+	case config.BotModePolling:
+		err = b.startPolling(gtgBot, listenAddr, mountable...)
+	default:
+		err = fmt.Errorf("unknown bot mode: %s", mode)
+	}
+	if err != nil {
+		return err
+	}
+
+	stopErr := make(chan error, 1)
+	var stopOnce sync.Once
+	stop := func() {
+		stopOnce.Do(func() {
+			b.shutdownServer()
+			stopErr <- b.updater.Stop()
+		})
+	}
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	b.updater.Idle()
+	stop()
+	return <-stopErr
+}
+
+// Ready checks that the Telegram Bot API is reachable with the bot token.
+func (b *Bot) Ready(ctx context.Context) error {
+	gtgBot := b.client.Load()
+	if gtgBot == nil {
+		return fmt.Errorf("bot is not started")
+	}
+	_, err := gtgBot.GetMeWithContext(ctx, nil)
+	return err
+}
+
+func (b *Bot) startWebhook(gtgBot *gotgbot.Bot, listenAddr string, mountable ...Mountable) error {
+	webhookURL := strings.TrimSpace(b.cfg.WebhookURL)
+	if webhookURL == "" {
+		return fmt.Errorf("webhook url is empty")
+	}
+
 	urlPath, err := normalizeWebhookPath(b.cfg.WebhookPath)
 	if err != nil {
 		return err
@@ -86,7 +156,7 @@ func (b *Bot) Start(ctx context.Context, mountable ...Mountable) error {
 		return err
 	}
 
-	if err := b.startWebhookServer(listenAddr, urlPath, mountable...); err != nil {
+	if err := b.startServer(listenAddr, urlPath, mountable...); err != nil {
 		_ = b.updater.Stop()
 		return err
 	}
@@ -98,44 +168,69 @@ func (b *Bot) Start(ctx context.Context, mountable ...Mountable) error {
 			Timeout: time.Second * time.Duration(b.cfg.RequestTimeoutSec),
 		},
 	}); err != nil {
-		b.shutdownWebhookServer()
+		b.shutdownServer()
 		_ = b.updater.Stop()
 		return err
 	}
 
 	log.Printf(
-		"bot started: listen_addr=%s webhook_url=%s webhook_path=%s drop_pending_updates=%t",
+		"bot started: mode=%s listen_addr=%s webhook_url=%s webhook_path=%s drop_pending_updates=%t",
+		config.BotModeWebhook,
 		listenAddr,
 		webhookURL,
 		urlPath,
 		b.cfg.DropPendingUpdates,
 	)
 
-	stopErr := make(chan error, 1)
-	var stopOnce sync.Once
-	stop := func() {
-		stopOnce.Do(func() {
-			b.shutdownWebhookServer()
-			stopErr <- b.updater.Stop()
-		})
+	return nil
+}
+
+func (b *Bot) startPolling(gtgBot *gotgbot.Bot, listenAddr string, mountable ...Mountable) error {
+	pollingTimeout := b.cfg.PollingTimeoutSec
+	if pollingTimeout < 0 {
+		pollingTimeout = 0
 	}
-	go func() {
-		<-ctx.Done()
-		stop()
-	}()
 
-	b.updater.Idle()
-	stop()
-	return <-stopErr
+	if err := b.updater.StartPolling(gtgBot, &ext.PollingOpts{
+		DropPendingUpdates:    b.cfg.DropPendingUpdates,
+		EnableWebhookDeletion: true,
+		GetUpdatesOpts: &gotgbot.GetUpdatesOpts{
+			Timeout: int64(pollingTimeout),
+			RequestOpts: &gotgbot.RequestOpts{
+				// Telegram holds the request for up to Timeout seconds, so the client must wait a bit longer.
+				Timeout: time.Second * time.Duration(pollingTimeout+b.cfg.RequestTimeoutSec),
+			},
+		},
+	}); err != nil {
+		return err
+	}
+
+	if err := b.startServer(listenAddr, "", mountable...); err != nil {
+		_ = b.updater.Stop()
+		return err
+	}
+
+	log.Printf(
+		"bot started: mode=%s listen_addr=%s polling_timeout_sec=%d drop_pending_updates=%t",
+		config.BotModePolling,
+		listenAddr,
+		pollingTimeout,
+		b.cfg.DropPendingUpdates,
+	)
+
+	return nil
 }
 
-func (b *Bot) startWebhookServer(listenAddr string, urlPath string, mountable ...Mountable) error {
+// startServer serves mountable endpoints and, when urlPath is set, the webhook endpoint.
+func (b *Bot) startServer(listenAddr string, urlPath string, mountable ...Mountable) error {
 	if b.server != nil {
-		return fmt.Errorf("webhook server already started")
+		return fmt.Errorf("http server already started")
 	}
 
 	e := echo.New()
-	e.POST(urlPath, echo.WrapHandler(b.updater.GetHandlerFunc("/")))
+	if urlPath != "" {
+		e.POST(urlPath, echo.WrapHandler(b.updater.GetHandlerFunc("/")))
+	}
 
 	for _, m := range mountable {
 		m.Mount(e)
@@ -156,7 +251,7 @@ func (b *Bot) startWebhookServer(listenAddr string, urlPath string, mountable ..
 	return nil
 }
 
-func (b *Bot) shutdownWebhookServer() {
+func (b *Bot) shutdownServer() {
 	if b.server == nil {
 		return
 	}
@@ -165,12 +260,20 @@ func (b *Bot) shutdownWebhookServer() {
 	defer cancel()
 	err := b.server.Shutdown(ctx)
 	if err != nil {
-		log.Printf("failed to shutdown webhook server: %v", err)
+		log.Printf("failed to shutdown http server: %v", err)
 	}
 
 	b.server = nil
 }
 
+func botMode(mode string) string {
+	m := strings.ToLower(strings.TrimSpace(mode))
+	if m == "" {
+		return config.BotModeWebhook
+	}
+	return m
+}
+
 func normalizeWebhookPath(path string) (string, error) {
 	p := strings.TrimSpace(path)
 	if p == "" {
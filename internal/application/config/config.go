@@ -2,20 +2,36 @@ package config
 
 import (
 	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/queue"
+	"music-bot-v2/internal/ratelimit"
 
 	"github.com/caarlos0/env/v9"
 )
 
+// Bot run modes selecting how updates are received from Telegram.
+const (
+	BotModeWebhook = "webhook"
+	BotModePolling = "polling"
+)
+
 type Config struct {
-	BotAPIToken        string        `env:"CONFIGURATION_BOT_API_TOKEN"`
-	DropPendingUpdates bool          `env:"CONFIGURATION_BOT_DROP_PENDING_UPDATES" envDefault:"true"`
-	RequestTimeoutSec  int           `env:"CONFIGURATION_BOT_REQUEST_TIMEOUT_SEC" envDefault:"10"`
-	WebhookURL         string        `env:"CONFIGURATION_BOT_WEBHOOK_URL"`
-	WebhookPath        string        `env:"CONFIGURATION_BOT_WEBHOOK_PATH" envDefault:"/bot"`
-	WebhookListenAddr  string        `env:"CONFIGURATION_BOT_WEBHOOK_LISTEN_ADDR" envDefault:":8080"`
-	WebhookSecretToken string        `env:"CONFIGURATION_BOT_WEBHOOK_SECRET_TOKEN"`
-	GoogleAPIKeys      []string      `env:"CONFIGURATION_GOOGLE_API_KEY" envSeparator:","`
-	Cacher             cacher.Config `envPrefix:"CONFIGURATION_CACHER_"`
+	BotAPIToken        string           `env:"CONFIGURATION_BOT_API_TOKEN"`
+	BotMode            string           `env:"CONFIGURATION_BOT_MODE" envDefault:"webhook"`
+	DropPendingUpdates bool             `env:"CONFIGURATION_BOT_DROP_PENDING_UPDATES" envDefault:"true"`
+	RequestTimeoutSec  int              `env:"CONFIGURATION_BOT_REQUEST_TIMEOUT_SEC" envDefault:"10"`
+	PollingTimeoutSec  int              `env:"CONFIGURATION_BOT_POLLING_TIMEOUT_SEC" envDefault:"9"`
+	WebhookURL         string           `env:"CONFIGURATION_BOT_WEBHOOK_URL"`
+	WebhookPath        string           `env:"CONFIGURATION_BOT_WEBHOOK_PATH" envDefault:"/bot"`
+	WebhookListenAddr  string           `env:"CONFIGURATION_BOT_WEBHOOK_LISTEN_ADDR" envDefault:":8080"`
+	WebhookSecretToken string           `env:"CONFIGURATION_BOT_WEBHOOK_SECRET_TOKEN"`
+	GoogleAPIKeys      []string         `env:"CONFIGURATION_GOOGLE_API_KEY" envSeparator:","`
+	GoogleDailyQuota   int              `env:"CONFIGURATION_GOOGLE_API_DAILY_QUOTA" envDefault:"10000"`
+	CobaltAPIURL       string           `env:"CONFIGURATION_COBALT_API_URL"`
+	CobaltAPIKey       string           `env:"CONFIGURATION_COBALT_API_KEY"`
+	AdminIDs           []int64          `env:"CONFIGURATION_BOT_ADMIN_IDS" envSeparator:","`
+	Cacher             cacher.Config    `envPrefix:"CONFIGURATION_CACHER_"`
+	RateLimit          ratelimit.Config `envPrefix:"CONFIGURATION_RATE_LIMIT_"`
+	Queue              queue.Config     `envPrefix:"CONFIGURATION_QUEUE_"`
 }
 
 func Get() (Config, error) {
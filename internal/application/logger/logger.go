@@ -8,6 +8,22 @@ import (
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+var (
+	updatesTotal = metrics.NewCounterVec(
+		"bot_updates_total",
+		"Telegram updates processed by type and result.",
+		"type", "result",
+	)
+	updateDuration = metrics.NewHistogramVec(
+		"bot_update_duration_seconds",
+		"Time spent handling Telegram updates by type.",
+		metrics.DefaultBuckets,
+		"type",
+	)
 )
 
 type updateProcessor struct {
@@ -22,13 +38,29 @@ func (up updateProcessor) ProcessUpdate(d *ext.Dispatcher, b *gotgbot.Bot, ctx *
 	start := time.Now()
 	if up.next == nil {
 		logUpdate(ctx, time.Since(start))
+		observeUpdate(ctx, time.Since(start), nil)
 		return nil
 	}
 	err := up.next.ProcessUpdate(d, b, ctx)
-	logUpdate(ctx, time.Since(start))
+	elapsed := time.Since(start)
+	logUpdate(ctx, elapsed)
+	observeUpdate(ctx, elapsed, err)
 	return err
 }
 
+func observeUpdate(ctx *ext.Context, elapsed time.Duration, err error) {
+	updateType := "nil"
+	if ctx != nil && ctx.Update != nil {
+		updateType, _ = describeUpdate(ctx)
+	}
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	updatesTotal.Inc(updateType, result)
+	updateDuration.Observe(elapsed.Seconds(), updateType)
+}
+
 func logUpdate(ctx *ext.Context, elapsed time.Duration) {
 	elapsedMs := elapsed.Milliseconds()
 	if ctx == nil || ctx.Update == nil {
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+const contentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler serves a registry in the Prometheus text exposition format.
+type Handler struct {
+	registry *Registry
+}
+
+func NewHandler(registry *Registry) *Handler {
+	if registry == nil {
+		registry = Default
+	}
+	return &Handler{registry: registry}
+}
+
+func (h *Handler) Mount(e *echo.Echo) {
+	e.GET("/metrics", h.metrics)
+}
+
+func (h *Handler) metrics(c echo.Context) error {
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().WriteHeader(http.StatusOK)
+	return h.registry.WriteText(c.Response())
+}
@@ -0,0 +1,282 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are latency histogram buckets in seconds.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Default is the registry the package-level constructors register into and the Handler serves.
+var Default = NewRegistry()
+
+type metric interface {
+	writeText(w *bufio.Writer)
+}
+
+// Registry holds metrics and renders them in the Prometheus text exposition format.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	r.metrics = append(r.metrics, m)
+	r.mu.Unlock()
+}
+
+// WriteText writes every registered metric in registration order.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeText(bw)
+	}
+	return bw.Flush()
+}
+
+// vec keeps one value per distinct set of label values.
+type vec[T any] struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	mu     sync.Mutex
+	series map[string]*series[T]
+	init   func() *T
+}
+
+type series[T any] struct {
+	labelValues []string
+	value       *T
+}
+
+func newVec[T any](name, help, kind string, labels []string, init func() *T) *vec[T] {
+	return &vec[T]{
+		name:   name,
+		help:   help,
+		kind:   kind,
+		labels: labels,
+		series: make(map[string]*series[T]),
+		init:   init,
+	}
+}
+
+// with runs fn on the series for labelValues under the vector lock, creating it on first use.
+func (v *vec[T]) with(labelValues []string, fn func(value *T)) {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	s, ok := v.series[key]
+	if !ok {
+		s = &series[T]{labelValues: append([]string(nil), labelValues...), value: v.init()}
+		v.series[key] = s
+	}
+	fn(s.value)
+}
+
+func (v *vec[T]) writeText(w *bufio.Writer, writeSeries func(w *bufio.Writer, labels string, value *T)) {
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.kind)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	keys := make([]string, 0, len(v.series))
+	for key := range v.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := v.series[key]
+		writeSeries(w, formatLabels(v.labels, s.labelValues), s.value)
+	}
+}
+
+// CounterVec is a monotonically increasing value per label set.
+type CounterVec struct {
+	vec *vec[float64]
+}
+
+// NewCounterVec registers a counter in the Default registry.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return Default.NewCounterVec(name, help, labels...)
+}
+
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{vec: newVec(name, help, "counter", labels, func() *float64 { return new(float64) })}
+	r.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.vec.with(labelValues, func(value *float64) { *value += delta })
+}
+
+func (c *CounterVec) writeText(w *bufio.Writer) {
+	c.vec.writeText(w, func(w *bufio.Writer, labels string, value *float64) {
+		fmt.Fprintf(w, "%s%s %s\n", c.vec.name, labels, formatFloat(*value))
+	})
+}
+
+// GaugeVec is a value per label set that can go up and down.
+type GaugeVec struct {
+	vec *vec[float64]
+}
+
+// NewGaugeVec registers a gauge in the Default registry.
+func NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return Default.NewGaugeVec(name, help, labels...)
+}
+
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	g := &GaugeVec{vec: newVec(name, help, "gauge", labels, func() *float64 { return new(float64) })}
+	r.register(g)
+	return g
+}
+
+func (g *GaugeVec) Set(v float64, labelValues ...string) {
+	g.vec.with(labelValues, func(value *float64) { *value = v })
+}
+
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	g.vec.with(labelValues, func(value *float64) { *value += delta })
+}
+
+func (g *GaugeVec) writeText(w *bufio.Writer) {
+	g.vec.writeText(w, func(w *bufio.Writer, labels string, value *float64) {
+		fmt.Fprintf(w, "%s%s %s\n", g.vec.name, labels, formatFloat(*value))
+	})
+}
+
+// HistogramVec counts observations into cumulative buckets per label set.
+type HistogramVec struct {
+	vec     *vec[histogram]
+	buckets []float64
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogramVec registers a histogram in the Default registry; nil buckets mean DefaultBuckets.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	return Default.NewHistogramVec(name, help, buckets, labels...)
+}
+
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{buckets: buckets}
+	h.vec = newVec(name, help, "histogram", labels, func() *histogram {
+		return &histogram{counts: make([]uint64, len(buckets))}
+	})
+	r.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.vec.with(labelValues, func(value *histogram) {
+		for i, upper := range h.buckets {
+			if v <= upper {
+				value.counts[i]++
+			}
+		}
+		value.count++
+		value.sum += v
+	})
+}
+
+func (h *HistogramVec) writeText(w *bufio.Writer) {
+	h.vec.writeText(w, func(w *bufio.Writer, labels string, value *histogram) {
+		for i, upper := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.vec.name, withLabel(labels, "le", formatFloat(upper)), value.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.vec.name, withLabel(labels, "le", "+Inf"), value.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.vec.name, labels, formatFloat(value.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.vec.name, labels, value.count)
+	})
+}
+
+func formatLabels(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabelValue(values[i]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func withLabel(labels string, name string, value string) string {
+	pair := name + `="` + escapeLabelValue(value) + `"`
+	if labels == "" {
+		return "{" + pair + "}"
+	}
+	return labels[:len(labels)-1] + "," + pair + "}"
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
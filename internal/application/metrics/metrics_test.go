@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteTextExpositionFormat(t *testing.T) {
+	registry := NewRegistry()
+
+	counter := registry.NewCounterVec("test_requests_total", "Requests.", "host", "status")
+	counter.Inc("example.com", "200")
+	counter.Add(2, "example.com", "200")
+	counter.Inc(`we"ird`, "500")
+
+	histogram := registry.NewHistogramVec("test_duration_seconds", "Durations.", []float64{0.1, 1}, "host")
+	histogram.Observe(0.05, "example.com")
+	histogram.Observe(0.5, "example.com")
+
+	var buf bytes.Buffer
+	if err := registry.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText error: %v", err)
+	}
+
+	expected := []string{
+		"# HELP test_requests_total Requests.",
+		"# TYPE test_requests_total counter",
+		`test_requests_total{host="example.com",status="200"} 3`,
+		`test_requests_total{host="we\"ird",status="500"} 1`,
+		"# TYPE test_duration_seconds histogram",
+		`test_duration_seconds_bucket{host="example.com",le="0.1"} 1`,
+		`test_duration_seconds_bucket{host="example.com",le="1"} 2`,
+		`test_duration_seconds_bucket{host="example.com",le="+Inf"} 2`,
+		`test_duration_seconds_sum{host="example.com"} 0.55`,
+		`test_duration_seconds_count{host="example.com"} 2`,
+	}
+	out := buf.String()
+	for _, line := range expected {
+		if !strings.Contains(out, line+"\n") {
+			t.Fatalf("missing line %q in output:\n%s", line, out)
+		}
+	}
+}
+
+func TestRegistriesAreIndependent(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewGaugeVec("test_isolated", "Isolated.", "name").Set(1, "a")
+
+	var buf bytes.Buffer
+	if err := Default.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText error: %v", err)
+	}
+	if strings.Contains(buf.String(), "test_isolated") {
+		t.Fatalf("metric of a fresh registry leaked into Default:\n%s", buf.String())
+	}
+}
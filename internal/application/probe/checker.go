@@ -0,0 +1,37 @@
+package probe
+
+import "context"
+
+// Checker is a single readiness dependency check.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// NewCheck adapts a function to a Checker.
+func NewCheck(name string, check func(ctx context.Context) error) Checker {
+	return checkFunc{name: name, check: check}
+}
+
+func (c checkFunc) Name() string {
+	return c.name
+}
+
+func (c checkFunc) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+type degradedCheck struct {
+	Checker
+}
+
+// Degraded wraps a check of a dependency the instance can partly work without, such as an API that only some
+// features need: its failure is reported as degraded and keeps the instance ready.
+func Degraded(checker Checker) Checker {
+	return degradedCheck{Checker: checker}
+}
@@ -1,21 +1,105 @@
 package probe
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
-type Handler struct{}
+const (
+	statusOK       = "ok"
+	statusDegraded = "degraded"
+	statusFail     = "fail"
 
-func NewHandler() *Handler {
-	return &Handler{}
+	defaultCheckTimeout = 3 * time.Second
+)
+
+type Handler struct {
+	checkers []Checker
+	timeout  time.Duration
+}
+
+// NewHandler creates probes; checkers are run by /readyz, /healthz never depends on them.
+func NewHandler(checkers ...Checker) *Handler {
+	return &Handler{
+		checkers: checkers,
+		timeout:  defaultCheckTimeout,
+	}
 }
 
 func (h *Handler) Mount(e *echo.Echo) {
 	e.GET("/healthz", h.health)
+	e.GET("/readyz", h.ready)
 }
 
 func (h *Handler) health(c echo.Context) error {
 	return c.String(http.StatusOK, "ok")
 }
+
+type readinessReport struct {
+	Status string        `json:"status"`
+	Checks []checkReport `json:"checks"`
+}
+
+type checkReport struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (h *Handler) ready(c echo.Context) error {
+	ctx, cancel := context.WithTimeout(c.Request().Context(), h.timeout)
+	defer cancel()
+
+	report := readinessReport{
+		Status: statusOK,
+		Checks: make([]checkReport, len(h.checkers)),
+	}
+
+	var wg sync.WaitGroup
+	for i, checker := range h.checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Checks[i] = runCheck(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	code := http.StatusOK
+	for _, check := range report.Checks {
+		switch check.Status {
+		case statusFail:
+			report.Status = statusFail
+			code = http.StatusServiceUnavailable
+		case statusDegraded:
+			if report.Status == statusOK {
+				report.Status = statusDegraded
+			}
+		}
+	}
+
+	return c.JSON(code, report)
+}
+
+func runCheck(ctx context.Context, checker Checker) checkReport {
+	start := time.Now()
+	err := checker.Check(ctx)
+	report := checkReport{
+		Name:      checker.Name(),
+		Status:    statusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		report.Status = statusFail
+		if _, ok := checker.(degradedCheck); ok {
+			report.Status = statusDegraded
+		}
+		report.Error = err.Error()
+	}
+	return report
+}
@@ -0,0 +1,45 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func readyz(t *testing.T, checkers ...Checker) (int, readinessReport) {
+	t.Helper()
+	e := echo.New()
+	NewHandler(checkers...).Mount(e)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report readinessReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	return rec.Code, report
+}
+
+func TestReadyzDegradedKeepsReady(t *testing.T) {
+	ok := NewCheck("cache", func(context.Context) error { return nil })
+	failing := func(context.Context) error { return errors.New("no quota") }
+
+	code, report := readyz(t, ok, Degraded(NewCheck("youtube", failing)))
+	if code != http.StatusOK || report.Status != statusDegraded {
+		t.Fatalf("degraded dependency: %d %+v", code, report)
+	}
+	if report.Checks[1].Status != statusDegraded || report.Checks[1].Error != "no quota" {
+		t.Fatalf("youtube check = %+v", report.Checks[1])
+	}
+
+	code, report = readyz(t, NewCheck("cache", failing), Degraded(NewCheck("youtube", failing)))
+	if code != http.StatusServiceUnavailable || report.Status != statusFail {
+		t.Fatalf("failing dependency: %d %+v", code, report)
+	}
+}
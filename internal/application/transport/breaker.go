@@ -0,0 +1,140 @@
+package transport
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the host's circuit is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerPolicy configures the per-host circuit breaker.
+type BreakerPolicy struct {
+	// FailureThreshold is the number of consecutive failures (network errors and 5xx) that opens the circuit.
+	FailureThreshold int
+	// OpenTimeout is how long the circuit stays open before a single probe request is let through.
+	OpenTimeout time.Duration
+}
+
+// DefaultBreakerPolicy opens a host's circuit after 5 consecutive failures for 30 seconds.
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+	}
+}
+
+func WithCircuitBreaker(policy BreakerPolicy) Option {
+	return func(client *Client) {
+		defaults := DefaultBreakerPolicy()
+		if policy.FailureThreshold <= 0 {
+			policy.FailureThreshold = defaults.FailureThreshold
+		}
+		if policy.OpenTimeout <= 0 {
+			policy.OpenTimeout = defaults.OpenTimeout
+		}
+		client.breakers = &breakerSet{
+			policy: policy,
+			hosts:  make(map[string]*breaker),
+			now:    time.Now,
+		}
+	}
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+type breakerSet struct {
+	policy BreakerPolicy
+	mu     sync.Mutex
+	hosts  map[string]*breaker
+	now    func() time.Time
+}
+
+type breaker struct {
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// allow reports whether a request to host may be sent, moving an expired open circuit to half-open.
+func (s *breakerSet) allow(host string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.hosts[host]
+	if b == nil {
+		return true
+	}
+	switch b.state {
+	case breakerOpen:
+		if s.now().Sub(b.openedAt) < s.policy.OpenTimeout {
+			return false
+		}
+		b.state = breakerHalfOpen
+		return true
+	case breakerHalfOpen:
+		// Only the probe request is in flight while half-open.
+		return false
+	default:
+		return true
+	}
+}
+
+func (s *breakerSet) record(host string, success bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	b := s.hosts[host]
+	if b == nil {
+		if success {
+			return
+		}
+		b = &breaker{}
+		s.hosts[host] = b
+	}
+
+	if success {
+		delete(s.hosts, host)
+		return
+	}
+
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= s.policy.FailureThreshold {
+		b.state = breakerOpen
+		b.openedAt = s.now()
+	}
+}
+
+// abandon returns a half-open circuit to open when its probe ended without an outcome (e.g. a canceled context),
+// letting the next request probe right away.
+func (s *breakerSet) abandon(host string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if b := s.hosts[host]; b != nil && b.state == breakerHalfOpen {
+		b.state = breakerOpen
+	}
+}
+
+func breakerFailure(resp Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500
+}
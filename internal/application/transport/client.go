@@ -5,16 +5,38 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+var (
+	requestsTotal = metrics.NewCounterVec(
+		"http_client_requests_total",
+		"Outgoing HTTP requests by host and status code.",
+		"host", "status",
+	)
+	requestDuration = metrics.NewHistogramVec(
+		"http_client_request_duration_seconds",
+		"Outgoing HTTP request latency by host.",
+		metrics.DefaultBuckets,
+		"host",
+	)
 )
 
 type Client struct {
 	httpClient *http.Client
 	headers    http.Header
+	retry      *RetryPolicy
+	breakers   *breakerSet
+	// idempotent marks every request as Request.Idempotent.
+	idempotent bool
 }
 
 type Option func(*Client)
@@ -61,6 +83,14 @@ func WithHeaders(headers http.Header) Option {
 	}
 }
 
+// WithIdempotentRequests lets the retry policy repeat every request of the client, whatever its method. It is
+// meant for clients of APIs that only look data up, where repeating a POST has no side effects.
+func WithIdempotentRequests() Option {
+	return func(client *Client) {
+		client.idempotent = true
+	}
+}
+
 type Request struct {
 	Method      string
 	URL         string
@@ -69,6 +99,8 @@ type Request struct {
 	Body        any
 	ContentType string
 	Encoder     Encoder
+	// Idempotent allows retrying methods that are not idempotent by definition, such as POST.
+	Idempotent bool
 }
 
 type Response struct {
@@ -108,6 +140,49 @@ func (c *Client) Do(ctx context.Context, request Request) (Response, error) {
 		parsedURL.RawQuery = query.Encode()
 	}
 
+	if c.idempotent {
+		request.Idempotent = true
+	}
+	attempts := c.retry.attempts(request, method)
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doOnce(ctx, method, parsedURL, request)
+		if attempt+1 >= attempts || !shouldRetry(ctx, resp, err) {
+			return resp, err
+		}
+		delay, ok := c.retry.delay(attempt, resp)
+		if !ok {
+			return resp, err
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return resp, err
+		}
+	}
+}
+
+func (c *Client) doOnce(ctx context.Context, method string, parsedURL *url.URL, request Request) (Response, error) {
+	host := parsedURL.Host
+	if !c.breakers.allow(host) {
+		requestsTotal.Inc(host, "circuit_open")
+		return Response{}, fmt.Errorf("%w: host %s", ErrCircuitOpen, host)
+	}
+
+	start := time.Now()
+	resp, err := c.send(ctx, method, parsedURL, request)
+	requestDuration.Observe(time.Since(start).Seconds(), host)
+	if err != nil {
+		requestsTotal.Inc(host, "error")
+	} else {
+		requestsTotal.Inc(host, strconv.Itoa(resp.StatusCode))
+	}
+	if ctx.Err() != nil {
+		c.breakers.abandon(host)
+	} else {
+		c.breakers.record(host, !breakerFailure(resp, err))
+	}
+	return resp, err
+}
+
+func (c *Client) send(ctx context.Context, method string, parsedURL *url.URL, request Request) (Response, error) {
 	body, contentType, err := c.encodeBody(ctx, request)
 	if err != nil {
 		return Response{}, err
@@ -228,6 +303,11 @@ func (c *Client) encodeBody(ctx context.Context, request Request) (io.ReadCloser
 	}
 }
 
+func isReader(body any) bool {
+	_, ok := body.(io.Reader)
+	return ok
+}
+
 func mergeHeaders(target http.Header, extra http.Header) {
 	for key, values := range extra {
 		target.Del(key)
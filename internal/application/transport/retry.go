@@ -0,0 +1,141 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures retries of failed requests with exponential backoff and jitter.
+// Only idempotent requests are retried, see Request.Idempotent for other methods.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt, it doubles with every further attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff; a Retry-After longer than MaxDelay stops retrying.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is a conservative policy suitable for upstream APIs.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   200 * time.Millisecond,
+		MaxDelay:    5 * time.Second,
+	}
+}
+
+func WithRetry(policy RetryPolicy) Option {
+	return func(client *Client) {
+		if policy.MaxAttempts <= 1 {
+			client.retry = nil
+			return
+		}
+		if policy.BaseDelay <= 0 {
+			policy.BaseDelay = DefaultRetryPolicy().BaseDelay
+		}
+		if policy.MaxDelay < policy.BaseDelay {
+			policy.MaxDelay = policy.BaseDelay
+		}
+		client.retry = &policy
+	}
+}
+
+func (p *RetryPolicy) attempts(request Request, method string) int {
+	if p == nil || !replayableBody(request) {
+		return 1
+	}
+	if !request.Idempotent && !idempotentMethod(method) {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// delay returns the wait before the attempt following the given zero-based one, or false to stop retrying.
+func (p *RetryPolicy) delay(attempt int, resp Response) (time.Duration, bool) {
+	backoff := p.BaseDelay
+	for i := 0; i < attempt && backoff < p.MaxDelay; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, p.MaxDelay)
+	// Equal jitter keeps at least half of the backoff while spreading concurrent retries apart.
+	backoff = backoff/2 + rand.N(backoff/2+1)
+
+	if retryAfter, ok := parseRetryAfter(resp.Headers, time.Now()); ok {
+		if retryAfter > p.MaxDelay {
+			return 0, false
+		}
+		backoff = max(backoff, retryAfter)
+	}
+	return backoff, true
+}
+
+func shouldRetry(ctx context.Context, resp Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, ErrCircuitOpen)
+	}
+	return retryableStatus(resp.StatusCode)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests ||
+		(code >= http.StatusInternalServerError && code != http.StatusNotImplemented)
+}
+
+func idempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// replayableBody reports whether the body can be encoded again for another attempt.
+func replayableBody(request Request) bool {
+	if request.Body == nil || request.Encoder != nil {
+		return true
+	}
+	switch request.Body.(type) {
+	case []byte, string:
+		return true
+	default:
+		return !isReader(request.Body)
+	}
+}
+
+func parseRetryAfter(headers http.Header, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(headers.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
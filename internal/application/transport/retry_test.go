@@ -0,0 +1,146 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Millisecond,
+		MaxDelay:    10 * time.Millisecond,
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := New(WithRetry(testRetryPolicy()))
+	resp, err := client.Do(context.Background(), Request{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || string(resp.Body) != "ok" {
+		t.Fatalf("unexpected response: %d %q", resp.StatusCode, resp.Body)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+}
+
+func TestClientDoesNotRetryPostUnlessIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := New(WithRetry(testRetryPolicy()))
+	resp, err := client.Do(context.Background(), Request{Method: http.MethodPost, URL: server.URL, Body: "payload"})
+	if err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Fatalf("expected a single attempt, got %d", calls.Load())
+	}
+
+	calls.Store(0)
+	_, err = client.Do(context.Background(), Request{Method: http.MethodPost, URL: server.URL, Body: "payload", Idempotent: true})
+	if err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts for idempotent POST, got %d", got)
+	}
+
+	calls.Store(0)
+	client = New(WithRetry(testRetryPolicy()), WithIdempotentRequests())
+	_, err = client.Do(context.Background(), Request{Method: http.MethodPost, URL: server.URL, Body: "payload"})
+	if err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("expected 3 attempts for POST on an idempotent client, got %d", got)
+	}
+}
+
+func TestClientHonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(WithRetry(testRetryPolicy()))
+	resp, err := client.Do(context.Background(), Request{URL: server.URL})
+	if err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests || calls.Load() != 1 {
+		t.Fatalf("expected Retry-After beyond MaxDelay to stop retrying, got %d after %d calls", resp.StatusCode, calls.Load())
+	}
+
+	if d, ok := parseRetryAfter(http.Header{"Retry-After": {"2"}}, time.Now()); !ok || d != 2*time.Second {
+		t.Fatalf("unexpected Retry-After parse: %v %t", d, ok)
+	}
+}
+
+func TestClientCircuitBreakerFailsFast(t *testing.T) {
+	var calls atomic.Int32
+	healthy := atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	now := time.Unix(0, 0)
+	client := New(WithCircuitBreaker(BreakerPolicy{FailureThreshold: 2, OpenTimeout: time.Minute}))
+	client.breakers.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Do(context.Background(), Request{URL: server.URL}); err != nil {
+			t.Fatalf("Do error: %v", err)
+		}
+	}
+	if _, err := client.Do(context.Background(), Request{URL: server.URL}); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("expected open circuit to skip the request, got %d calls", got)
+	}
+
+	now = now.Add(time.Minute)
+	healthy.Store(true)
+	resp, err := client.Do(context.Background(), Request{URL: server.URL})
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected half-open probe to succeed, got %v", err)
+	}
+	if _, err := client.Do(context.Background(), Request{URL: server.URL}); err != nil {
+		t.Fatalf("expected closed circuit, got %v", err)
+	}
+}
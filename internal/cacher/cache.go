@@ -0,0 +1,35 @@
+package cacher
+
+import (
+	"context"
+	"time"
+)
+
+// Cache backends selectable via Config.Backend.
+const (
+	BackendRedis  = "redis"
+	BackendMemory = "memory"
+)
+
+// Cache is a string key-value cache implemented by Redis and Memory.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+	Delete(ctx context.Context, key string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+	Ping(ctx context.Context) error
+}
+
+// New creates a cache for the database using the configured backend, ttl=0 means entries never expire.
+// In memory, durable databases are unbounded: the capacity only limits state that can be fetched again.
+func New(db int, ttl time.Duration) Cache {
+	cfg := getConfig()
+	if cfg.Backend == BackendMemory {
+		capacity := cfg.MemoryCapacity
+		if Durable(db) {
+			capacity = 0
+		}
+		return NewMemory(db, ttl, capacity)
+	}
+	return NewRedis(db, ttl)
+}
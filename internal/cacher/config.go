@@ -7,18 +7,22 @@ var (
 	configMu      sync.RWMutex
 )
 
-// Config describes Redis cache settings.
+// Config describes cache settings.
 type Config struct {
-	RedisAddr     string `env:"REDIS_ADDR" envDefault:"localhost:6379"`
-	RedisUsername string `env:"REDIS_USERNAME" envDefault:"app"`
-	RedisPassword string `env:"REDIS_PASSWORD" envDefault:"local-redis-pass"`
+	Backend        string `env:"BACKEND" envDefault:"redis"`
+	RedisAddr      string `env:"REDIS_ADDR" envDefault:"localhost:6379"`
+	RedisUsername  string `env:"REDIS_USERNAME" envDefault:"app"`
+	RedisPassword  string `env:"REDIS_PASSWORD" envDefault:"local-redis-pass"`
+	MemoryCapacity int    `env:"MEMORY_CAPACITY" envDefault:"10000"`
 }
 
 func defaultConfig() Config {
 	return Config{
-		RedisAddr:     "localhost:6379",
-		RedisUsername: "app",
-		RedisPassword: "local-redis-pass",
+		Backend:        BackendRedis,
+		RedisAddr:      "localhost:6379",
+		RedisUsername:  "app",
+		RedisPassword:  "local-redis-pass",
+		MemoryCapacity: 10000,
 	}
 }
 
@@ -40,5 +44,8 @@ func getConfig() Config {
 	if cfg.RedisUsername == "" {
 		cfg.RedisUsername = defaults.RedisUsername
 	}
+	if cfg.Backend == "" {
+		cfg.Backend = defaults.Backend
+	}
 	return cfg
 }
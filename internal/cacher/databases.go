@@ -1,5 +1,7 @@
 package cacher
 
+import "strconv"
+
 const (
 	SearchCacheDB = 0
 	TokenCacheDB  = 1
@@ -7,4 +9,53 @@ const (
 	QueryCacheDB = 2
 	PanelCacheDB = 3
 	AudioCacheDB = 4
+
+	PlaylistCacheDB = 5
+
+	RateLimitDB = 6
+	LinkCacheDB = 7
+	LockDB      = 8
+
+	LanguageDB  = 9
+	SettingsDB  = 10
+	HistoryDB   = 11
+	FavoritesDB = 12
 )
+
+var dbNames = map[int]string{
+	SearchCacheDB:   "search",
+	TokenCacheDB:    "token",
+	QueryCacheDB:    "query",
+	PanelCacheDB:    "panel",
+	AudioCacheDB:    "audio",
+	PlaylistCacheDB: "playlist",
+	RateLimitDB:     "ratelimit",
+	LinkCacheDB:     "link",
+	LockDB:          "lock",
+	LanguageDB:      "language",
+	SettingsDB:      "settings",
+	HistoryDB:       "history",
+	FavoritesDB:     "favorites",
+}
+
+// durableDBs hold user data rather than state that can be fetched or computed again.
+var durableDBs = map[int]bool{
+	PlaylistCacheDB: true,
+	LanguageDB:      true,
+	SettingsDB:      true,
+	HistoryDB:       true,
+	FavoritesDB:     true,
+}
+
+// Durable reports whether the database holds user data, which must never be evicted.
+func Durable(db int) bool {
+	return durableDBs[db]
+}
+
+// DBName returns a human-readable name of the cache database for logs and metrics.
+func DBName(db int) string {
+	if name, ok := dbNames[db]; ok {
+		return name
+	}
+	return strconv.Itoa(db)
+}
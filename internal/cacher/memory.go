@@ -0,0 +1,137 @@
+package cacher
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory is an in-process LRU cache with string keys and values and the same semantics as Redis.
+type Memory struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	capacity int
+	dbName   string
+	items    map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// NewMemory creates new Memory object, ttl=0 means entries never expire and capacity<=0 means unbounded.
+// The least recently used entry is evicted once capacity is reached.
+func NewMemory(db int, ttl time.Duration, capacity int) *Memory {
+	return &Memory{
+		ttl:      ttl,
+		capacity: capacity,
+		dbName:   DBName(db),
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+func (c *Memory) Set(ctx context.Context, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if c.ttl > 0 {
+		expiresAt = c.now().Add(c.ttl)
+	}
+
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.items[key] = c.order.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.capacity > 0 && c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+func (c *Memory) Get(ctx context.Context, key string) (string, bool, error) {
+	if err := ctx.Err(); err != nil {
+		cacheRequests.Inc(c.dbName, "error")
+		return "", false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		cacheRequests.Inc(c.dbName, "miss")
+		return "", false, nil
+	}
+	entry := element.Value.(*memoryEntry)
+	if c.expired(entry) {
+		c.remove(element)
+		cacheRequests.Inc(c.dbName, "miss")
+		return "", false, nil
+	}
+	c.order.MoveToFront(element)
+	cacheRequests.Inc(c.dbName, "hit")
+	return entry.value, true, nil
+}
+
+func (c *Memory) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+	return nil
+}
+
+func (c *Memory) DeletePrefix(ctx context.Context, prefix string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, element := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(element)
+		}
+	}
+	return nil
+}
+
+// Ping always succeeds, the cache lives in the process.
+func (c *Memory) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (c *Memory) expired(entry *memoryEntry) bool {
+	return !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt)
+}
+
+// remove drops the element from the cache; c.mu must be held.
+func (c *Memory) remove(element *list.Element) {
+	entry := c.order.Remove(element).(*memoryEntry)
+	delete(c.items, entry.key)
+}
@@ -0,0 +1,98 @@
+package cacher
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemorySetGetDelete(t *testing.T) {
+	cache := NewMemory(0, 0, 0)
+	ctx := context.Background()
+
+	for key, value := range map[string]string{"req#0": "a", "req#1": "b", "other#0": "c"} {
+		if err := cache.Set(ctx, key, value); err != nil {
+			t.Fatalf("set error: %v", err)
+		}
+	}
+	if got, ok, err := cache.Get(ctx, "req#0"); err != nil || !ok || got != "a" {
+		t.Fatalf("expected req#0=a, got %q ok=%t err=%v", got, ok, err)
+	}
+
+	if err := cache.DeletePrefix(ctx, "req"); err != nil {
+		t.Fatalf("delete prefix error: %v", err)
+	}
+	if _, ok, _ := cache.Get(ctx, "req#1"); ok {
+		t.Fatalf("expected req#1 to be deleted")
+	}
+	if _, ok, _ := cache.Get(ctx, "other#0"); !ok {
+		t.Fatalf("expected other#0 to remain")
+	}
+
+	if err := cache.Delete(ctx, "other"); err != nil {
+		t.Fatalf("delete error: %v", err)
+	}
+	if _, ok, _ := cache.Get(ctx, "other#0"); !ok {
+		t.Fatalf("expected delete to match the whole key only")
+	}
+	if err := cache.Delete(ctx, "other#0"); err != nil {
+		t.Fatalf("delete error: %v", err)
+	}
+	if _, ok, _ := cache.Get(ctx, "other#0"); ok {
+		t.Fatalf("expected other#0 to be deleted")
+	}
+}
+
+func TestMemoryExpiresEntries(t *testing.T) {
+	now := time.Unix(0, 0)
+	cache := NewMemory(0, time.Minute, 0)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "a", "1"); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+	now = now.Add(59 * time.Second)
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Fatalf("expected key before ttl")
+	}
+	now = now.Add(time.Second)
+	if _, ok, _ := cache.Get(ctx, "a"); ok {
+		t.Fatalf("expected key to expire after ttl")
+	}
+}
+
+func TestMemoryEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := NewMemory(0, 0, 2)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "a", "1")
+	_ = cache.Set(ctx, "b", "2")
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Fatalf("expected a to exist")
+	}
+	_ = cache.Set(ctx, "c", "3")
+
+	if _, ok, _ := cache.Get(ctx, "b"); ok {
+		t.Fatalf("expected least recently used key b to be evicted")
+	}
+	if _, ok, _ := cache.Get(ctx, "a"); !ok {
+		t.Fatalf("expected recently used key a to remain")
+	}
+}
+
+func TestNewKeepsDurableDatabasesUnbounded(t *testing.T) {
+	SetConfig(Config{Backend: BackendMemory, MemoryCapacity: 1})
+	defer SetConfig(defaultConfig())
+	ctx := context.Background()
+
+	for _, db := range []int{SearchCacheDB, PlaylistCacheDB} {
+		cache := New(db, 0)
+		_ = cache.Set(ctx, "first", "1")
+		_ = cache.Set(ctx, "second", "2")
+		_, ok, _ := cache.Get(ctx, "first")
+		if ok != Durable(db) {
+			t.Errorf("db %s kept the first entry: %t, want %t", DBName(db), ok, Durable(db))
+		}
+	}
+}
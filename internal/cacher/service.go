@@ -6,28 +6,43 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+var cacheRequests = metrics.NewCounterVec(
+	"cache_requests_total",
+	"Cache lookups by database and result (hit, miss, error).",
+	"db", "result",
 )
 
 // Redis is a Redis-backed cache with string keys and values.
 type Redis struct {
 	client *redis.Client
 	ttl    time.Duration
+	dbName string
 }
 
 // NewRedis creates new Redis object, ttl=0 means it is never expire.
 func NewRedis(db int, ttl time.Duration) *Redis {
-	cfg := getConfig()
 	return &Redis{
-		ttl: ttl,
-		client: redis.NewClient(&redis.Options{
-			Addr:     cfg.RedisAddr,
-			Username: cfg.RedisUsername,
-			Password: cfg.RedisPassword,
-			DB:       db,
-		}),
+		ttl:    ttl,
+		dbName: DBName(db),
+		client: NewRedisClient(db),
 	}
 }
 
+// NewRedisClient creates a client of the configured Redis server for packages that need more than a cache.
+func NewRedisClient(db int) *redis.Client {
+	cfg := getConfig()
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.RedisAddr,
+		Username: cfg.RedisUsername,
+		Password: cfg.RedisPassword,
+		DB:       db,
+	})
+}
+
 func (c *Redis) Set(ctx context.Context, key, value string) error {
 	return c.client.Set(ctx, key, value, c.ttl).Err()
 }
@@ -35,14 +50,21 @@ func (c *Redis) Set(ctx context.Context, key, value string) error {
 func (c *Redis) Get(ctx context.Context, key string) (string, bool, error) {
 	value, err := c.client.Get(ctx, key).Result()
 	if errors.Is(err, redis.Nil) {
+		cacheRequests.Inc(c.dbName, "miss")
 		return "", false, nil
 	}
 	if err != nil {
+		cacheRequests.Inc(c.dbName, "error")
 		return "", false, err
 	}
+	cacheRequests.Inc(c.dbName, "hit")
 	return value, true, nil
 }
 
+func (c *Redis) Delete(ctx context.Context, key string) error {
+	return c.client.Del(ctx, key).Err()
+}
+
 func (c *Redis) DeletePrefix(ctx context.Context, prefix string) error {
 	pattern := prefix + "*"
 	iter := c.client.Scan(ctx, 0, pattern, 100).Iterator()
@@ -68,3 +90,8 @@ func (c *Redis) DeletePrefix(ctx context.Context, prefix string) error {
 	}
 	return nil
 }
+
+// Ping checks that the Redis database is reachable.
+func (c *Redis) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
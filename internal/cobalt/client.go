@@ -0,0 +1,110 @@
+package cobalt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"music-bot-v2/internal/application/transport"
+)
+
+const videoURLPrefix = "https://www.youtube.com/watch?v="
+
+// Client talks to a cobalt (https://github.com/imputnet/cobalt) API instance.
+type Client struct {
+	httpClient *transport.Client
+	baseURL    string
+	apiKey     string
+}
+
+func NewClient(baseURL string, apiKey string, httpClient *transport.Client) *Client {
+	if httpClient == nil {
+		httpClient = transport.New()
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(strings.TrimSpace(baseURL), "/"),
+		apiKey:     strings.TrimSpace(apiKey),
+	}
+}
+
+type requestPayload struct {
+	URL          string `json:"url"`
+	DownloadMode string `json:"downloadMode"`
+	AudioFormat  string `json:"audioFormat"`
+}
+
+type responsePayload struct {
+	Status string `json:"status"`
+	URL    string `json:"url"`
+	Error  *struct {
+		Code string `json:"code"`
+	} `json:"error"`
+}
+
+func (c *Client) MP3Link(ctx context.Context, id string) (string, error) {
+	if strings.TrimSpace(id) == "" {
+		return "", errors.New("id is empty")
+	}
+	if c.baseURL == "" {
+		return "", errors.New("base url is empty")
+	}
+
+	headers := http.Header{}
+	headers.Set("Accept", "application/json")
+	if c.apiKey != "" {
+		headers.Set("Authorization", "Api-Key "+c.apiKey)
+	}
+
+	request := transport.Request{
+		Method: http.MethodPost,
+		URL:    c.baseURL + "/",
+		Body: requestPayload{
+			URL:          videoURLPrefix + id,
+			DownloadMode: "audio",
+			AudioFormat:  "mp3",
+		},
+		Encoder: transport.JSONEncoder,
+		Headers: headers,
+	}
+
+	resp, payload, err := transport.DoDecode(ctx, c.httpClient, request, transport.JSONDecoder[responsePayload])
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return "", fmt.Errorf("request failed: %s", formatAPIError(resp, payload))
+	}
+
+	switch payload.Status {
+	case "tunnel", "redirect":
+	default:
+		return "", fmt.Errorf("unexpected status: %s", formatAPIError(resp, payload))
+	}
+
+	link := strings.TrimSpace(payload.URL)
+	if link == "" {
+		return "", errors.New("link is empty")
+	}
+
+	return link, nil
+}
+
+func formatAPIError(resp transport.Response, payload responsePayload) string {
+	if payload.Error != nil && strings.TrimSpace(payload.Error.Code) != "" {
+		return payload.Error.Code
+	}
+	if payload.Status != "" {
+		return payload.Status
+	}
+	if len(resp.Body) > 0 {
+		body := strings.TrimSpace(string(resp.Body))
+		if body != "" {
+			return body
+		}
+	}
+	return resp.Status
+}
@@ -0,0 +1,199 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+const (
+	// DefaultMaxBytes is Telegram's limit for files uploaded by bots.
+	DefaultMaxBytes = 50 << 20
+
+	defaultTimeout = 5 * time.Minute
+	sniffBytes     = 512
+)
+
+var (
+	ErrTooLarge              = errors.New("file is too large")
+	ErrEmpty                 = errors.New("file is empty")
+	ErrUnexpectedContentType = errors.New("unexpected content type")
+)
+
+var downloadsTotal = metrics.NewCounterVec(
+	"audio_downloads_total",
+	"Server-side audio downloads by result.",
+	"result",
+)
+
+// Downloader fetches remote audio files into temporary files, so a download never holds more than a small
+// buffer in memory.
+type Downloader struct {
+	httpClient *http.Client
+	maxBytes   int64
+	dir        string
+}
+
+type Option func(*Downloader)
+
+func New(options ...Option) *Downloader {
+	downloader := &Downloader{
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		maxBytes:   DefaultMaxBytes,
+	}
+	for _, option := range options {
+		option(downloader)
+	}
+	return downloader
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(downloader *Downloader) {
+		if httpClient != nil {
+			downloader.httpClient = httpClient
+		}
+	}
+}
+
+// WithMaxBytes limits the size of downloaded files.
+func WithMaxBytes(maxBytes int64) Option {
+	return func(downloader *Downloader) {
+		if maxBytes > 0 {
+			downloader.maxBytes = maxBytes
+		}
+	}
+}
+
+// WithTempDir sets the directory for downloaded files, the system temp directory by default.
+func WithTempDir(dir string) Option {
+	return func(downloader *Downloader) {
+		downloader.dir = dir
+	}
+}
+
+// File is a downloaded file. Close removes it from disk.
+type File struct {
+	*os.File
+	Size        int64
+	ContentType string
+}
+
+func (f *File) Close() error {
+	closeErr := f.File.Close()
+	if err := os.Remove(f.Name()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return closeErr
+}
+
+// Fetch downloads url into a temporary file positioned at its start. Responses that are not audio, such as
+// converter error pages, and files over the size limit are rejected.
+func (d *Downloader) Fetch(ctx context.Context, url string) (*File, error) {
+	file, err := d.fetch(ctx, url)
+	switch {
+	case err == nil:
+		downloadsTotal.Inc("success")
+	case errors.Is(err, ErrTooLarge):
+		downloadsTotal.Inc("too_large")
+	case errors.Is(err, ErrUnexpectedContentType):
+		downloadsTotal.Inc("bad_content_type")
+	default:
+		downloadsTotal.Inc("error")
+	}
+	return file, err
+}
+
+func (d *Downloader) fetch(ctx context.Context, url string) (*File, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := d.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("download failed: %s", response.Status)
+	}
+	if response.ContentLength > d.maxBytes {
+		return nil, fmt.Errorf("%w: %d bytes", ErrTooLarge, response.ContentLength)
+	}
+
+	contentType := mediaType(response.Header.Get("Content-Type"))
+	if !acceptedContentType(contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedContentType, contentType)
+	}
+
+	file, err := os.CreateTemp(d.dir, "audio-*")
+	if err != nil {
+		return nil, err
+	}
+	result := &File{File: file, ContentType: contentType}
+
+	// One byte over the limit is enough to tell the file is too large.
+	size, err := io.Copy(file, io.LimitReader(response.Body, d.maxBytes+1))
+	if err != nil {
+		_ = result.Close()
+		return nil, err
+	}
+	result.Size = size
+
+	if err := d.validate(result); err != nil {
+		_ = result.Close()
+		return nil, err
+	}
+	return result, nil
+}
+
+func (d *Downloader) validate(file *File) error {
+	if file.Size == 0 {
+		return ErrEmpty
+	}
+	if file.Size > d.maxBytes {
+		return fmt.Errorf("%w: over %d bytes", ErrTooLarge, d.maxBytes)
+	}
+
+	head := make([]byte, sniffBytes)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	// Converters answer some failures with 200 and an HTML page, whatever the declared type.
+	if sniffed := http.DetectContentType(head[:n]); strings.HasPrefix(sniffed, "text/") {
+		return fmt.Errorf("%w: content looks like %s", ErrUnexpectedContentType, sniffed)
+	}
+
+	_, err = file.Seek(0, io.SeekStart)
+	return err
+}
+
+func mediaType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(contentType))
+	}
+	return mediaType
+}
+
+// acceptedContentType allows audio types and the generic binary types converters often use instead.
+func acceptedContentType(contentType string) bool {
+	switch {
+	case strings.HasPrefix(contentType, "audio/"):
+		return true
+	case contentType == "", contentType == "application/octet-stream", contentType == "binary/octet-stream",
+		contentType == "application/force-download":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,80 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+var mp3Header = []byte("ID3\x04\x00\x00\x00\x00\x00\x00")
+
+func serve(t *testing.T, contentType string, body []byte, chunked bool) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		if chunked {
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFetchStoresFile(t *testing.T) {
+	body := append(append([]byte{}, mp3Header...), bytes.Repeat([]byte{0xFF}, 1000)...)
+	server := serve(t, "audio/mpeg", body, false)
+
+	file, err := New(WithTempDir(t.TempDir())).Fetch(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Fetch error: %v", err)
+	}
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if !bytes.Equal(got, body) || file.Size != int64(len(body)) || file.ContentType != "audio/mpeg" {
+		t.Fatalf("unexpected file: size=%d type=%s", file.Size, file.ContentType)
+	}
+
+	name := file.Name()
+	if err := file.Close(); err != nil {
+		t.Fatalf("Close error: %v", err)
+	}
+	if _, err := os.Stat(name); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected file to be removed, stat err=%v", err)
+	}
+}
+
+func TestFetchRejectsLargeFiles(t *testing.T) {
+	body := append(append([]byte{}, mp3Header...), make([]byte, 100)...)
+	for _, chunked := range []bool{false, true} {
+		server := serve(t, "audio/mpeg", body, chunked)
+		_, err := New(WithMaxBytes(64), WithTempDir(t.TempDir())).Fetch(context.Background(), server.URL)
+		if !errors.Is(err, ErrTooLarge) {
+			t.Fatalf("chunked=%v: expected ErrTooLarge, got %v", chunked, err)
+		}
+	}
+}
+
+func TestFetchRejectsNonAudio(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/html; charset=utf-8", mp3Header},
+		{"application/octet-stream", []byte("<!DOCTYPE html><html><body>Error</body></html>")},
+	}
+	for _, tt := range tests {
+		server := serve(t, tt.contentType, tt.body, false)
+		_, err := New(WithTempDir(t.TempDir())).Fetch(context.Background(), server.URL)
+		if !errors.Is(err, ErrUnexpectedContentType) {
+			t.Fatalf("%s: expected ErrUnexpectedContentType, got %v", tt.contentType, err)
+		}
+	}
+}
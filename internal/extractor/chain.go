@@ -0,0 +1,209 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = time.Minute
+	maxCooldown             = 15 * time.Minute
+)
+
+var extractorRequests = metrics.NewCounterVec(
+	"link_extractor_requests_total",
+	"MP3 link extraction attempts by provider and result.",
+	"provider", "result",
+)
+
+// LinkExtractor resolves a YouTube video ID into a direct MP3 link.
+type LinkExtractor interface {
+	MP3Link(ctx context.Context, id string) (string, error)
+}
+
+// Provider is a named link extractor participating in a Chain.
+type Provider struct {
+	Name      string
+	Extractor LinkExtractor
+}
+
+// Stats is a snapshot of a provider's health.
+type Stats struct {
+	Name                string
+	Successes           uint64
+	Failures            uint64
+	ConsecutiveFailures int
+	SkippedUntil        time.Time
+}
+
+// Chain tries providers in order and returns the first link resolved.
+// Providers that fail repeatedly are skipped for a cooldown that doubles with every further failure.
+type Chain struct {
+	providers        []*providerState
+	failureThreshold int
+	cooldown         time.Duration
+	now              func() time.Time
+}
+
+type providerState struct {
+	mu       sync.Mutex
+	provider Provider
+	stats    Stats
+}
+
+type Option func(*Chain)
+
+func NewChain(providers []Provider, options ...Option) *Chain {
+	chain := &Chain{
+		failureThreshold: defaultFailureThreshold,
+		cooldown:         defaultCooldown,
+		now:              time.Now,
+	}
+	for _, provider := range providers {
+		if provider.Extractor == nil {
+			continue
+		}
+		chain.providers = append(chain.providers, &providerState{
+			provider: provider,
+			stats:    Stats{Name: provider.Name},
+		})
+	}
+	for _, option := range options {
+		option(chain)
+	}
+	return chain
+}
+
+// WithFailureThreshold sets how many consecutive failures put a provider on cooldown.
+func WithFailureThreshold(threshold int) Option {
+	return func(chain *Chain) {
+		if threshold > 0 {
+			chain.failureThreshold = threshold
+		}
+	}
+}
+
+// WithCooldown sets the initial time a failing provider is skipped for.
+func WithCooldown(cooldown time.Duration) Option {
+	return func(chain *Chain) {
+		if cooldown > 0 {
+			chain.cooldown = cooldown
+		}
+	}
+}
+
+func (c *Chain) MP3Link(ctx context.Context, id string) (string, error) {
+	if strings.TrimSpace(id) == "" {
+		return "", errors.New("id is empty")
+	}
+	if len(c.providers) == 0 {
+		return "", errors.New("no link extractors configured")
+	}
+
+	// Providers on cooldown are kept as a last resort, so a link is still attempted when every provider is failing.
+	now := c.now()
+	active := make([]*providerState, 0, len(c.providers))
+	var skipped []*providerState
+	for _, state := range c.providers {
+		if state.skipped(now) {
+			skipped = append(skipped, state)
+			continue
+		}
+		active = append(active, state)
+	}
+
+	var errs []error
+	for _, state := range append(active, skipped...) {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		start := c.now()
+		link, err := state.provider.Extractor.MP3Link(ctx, id)
+		elapsed := c.now().Sub(start)
+		if err != nil {
+			if ctx.Err() == nil {
+				state.recordFailure(c.now(), c.failureThreshold, c.cooldown)
+			}
+			extractorRequests.Inc(state.provider.Name, "failure")
+			log.Printf("extractor provider=%s id=%s duration_ms=%d err=%v", state.provider.Name, id, elapsed.Milliseconds(), err)
+			errs = append(errs, fmt.Errorf("%s: %w", state.provider.Name, err))
+			continue
+		}
+
+		state.recordSuccess()
+		extractorRequests.Inc(state.provider.Name, "success")
+		log.Printf("extractor provider=%s id=%s duration_ms=%d served", state.provider.Name, id, elapsed.Milliseconds())
+		return link, nil
+	}
+
+	return "", fmt.Errorf("all link extractors failed: %w", errors.Join(errs...))
+}
+
+// Ready reports an error when every provider is on cooldown.
+func (c *Chain) Ready(ctx context.Context) error {
+	if len(c.providers) == 0 {
+		return errors.New("no link extractors configured")
+	}
+	now := c.now()
+	for _, state := range c.providers {
+		if !state.skipped(now) {
+			return nil
+		}
+	}
+	return errors.New("all link extractors are failing")
+}
+
+// Stats returns a snapshot of every provider's health in chain order.
+func (c *Chain) Stats() []Stats {
+	stats := make([]Stats, 0, len(c.providers))
+	for _, state := range c.providers {
+		state.mu.Lock()
+		stats = append(stats, state.stats)
+		state.mu.Unlock()
+	}
+	return stats
+}
+
+func (s *providerState) skipped(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.Before(s.stats.SkippedUntil)
+}
+
+func (s *providerState) recordSuccess() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Successes++
+	s.stats.ConsecutiveFailures = 0
+	s.stats.SkippedUntil = time.Time{}
+}
+
+func (s *providerState) recordFailure(now time.Time, threshold int, cooldown time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Failures++
+	s.stats.ConsecutiveFailures++
+	if s.stats.ConsecutiveFailures < threshold {
+		return
+	}
+
+	backoff := cooldown
+	for i := threshold; i < s.stats.ConsecutiveFailures && backoff < maxCooldown; i++ {
+		backoff *= 2
+	}
+	if backoff > maxCooldown {
+		backoff = maxCooldown
+	}
+	s.stats.SkippedUntil = now.Add(backoff)
+	log.Printf("extractor provider=%s skipped for %s after %d consecutive failures", s.provider.Name, backoff, s.stats.ConsecutiveFailures)
+}
@@ -0,0 +1,93 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type stubExtractor struct {
+	link  string
+	err   error
+	calls int
+}
+
+func (s *stubExtractor) MP3Link(ctx context.Context, id string) (string, error) {
+	s.calls++
+	return s.link, s.err
+}
+
+func TestChainFallsBackToNextProvider(t *testing.T) {
+	first := &stubExtractor{err: errors.New("down")}
+	second := &stubExtractor{link: "https://example.com/a.mp3"}
+	chain := NewChain([]Provider{
+		{Name: "first", Extractor: first},
+		{Name: "second", Extractor: second},
+	})
+
+	link, err := chain.MP3Link(context.Background(), "id")
+	if err != nil {
+		t.Fatalf("MP3Link error: %v", err)
+	}
+	if link != second.link {
+		t.Fatalf("expected link %q, got %q", second.link, link)
+	}
+
+	stats := chain.Stats()
+	if stats[0].Failures != 1 || stats[1].Successes != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestChainSkipsFailingProviderUntilCooldownEnds(t *testing.T) {
+	now := time.Unix(0, 0)
+	first := &stubExtractor{err: errors.New("down")}
+	second := &stubExtractor{link: "https://example.com/a.mp3"}
+	chain := NewChain([]Provider{
+		{Name: "first", Extractor: first},
+		{Name: "second", Extractor: second},
+	}, WithFailureThreshold(2), WithCooldown(time.Minute))
+	chain.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := chain.MP3Link(context.Background(), "id"); err != nil {
+			t.Fatalf("MP3Link error: %v", err)
+		}
+	}
+	if first.calls != 2 {
+		t.Fatalf("expected 2 calls to failing provider, got %d", first.calls)
+	}
+
+	if _, err := chain.MP3Link(context.Background(), "id"); err != nil {
+		t.Fatalf("MP3Link error: %v", err)
+	}
+	if first.calls != 2 {
+		t.Fatalf("expected failing provider to be skipped, got %d calls", first.calls)
+	}
+
+	now = now.Add(time.Minute)
+	first.err = nil
+	first.link = "https://example.com/b.mp3"
+	link, err := chain.MP3Link(context.Background(), "id")
+	if err != nil {
+		t.Fatalf("MP3Link error: %v", err)
+	}
+	if link != first.link {
+		t.Fatalf("expected recovered provider link %q, got %q", first.link, link)
+	}
+}
+
+func TestChainTriesSkippedProvidersAsLastResort(t *testing.T) {
+	only := &stubExtractor{err: errors.New("down")}
+	chain := NewChain([]Provider{{Name: "only", Extractor: only}}, WithFailureThreshold(1))
+
+	if _, err := chain.MP3Link(context.Background(), "id"); err == nil {
+		t.Fatalf("expected error")
+	}
+	only.err = nil
+	only.link = "https://example.com/a.mp3"
+	if _, err := chain.MP3Link(context.Background(), "id"); err != nil {
+		t.Fatalf("expected skipped provider to be tried, got %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package favorites
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"music-bot-v2/internal/settings"
+)
+
+// MaxFavorites is how many tracks a user can save.
+const MaxFavorites = 500
+
+var (
+	ErrNotFound     = errors.New("favorite not found")
+	ErrExists       = errors.New("track already in favorites")
+	ErrLimitReached = errors.New("favorites limit reached")
+)
+
+type cacherService interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+}
+
+// Favorite is a track the user saved from a delivered audio message.
+type Favorite struct {
+	VideoID string `json:"video_id"`
+	Title   string `json:"title"`
+	// FileID is the Telegram file the track was saved from, it resends the track without a conversion.
+	FileID string `json:"file_id,omitempty"`
+	// Format is how FileID was sent, a file sent as audio cannot be resent as a document and the other way round.
+	// Favorites saved before it was recorded have none.
+	Format  settings.Format `json:"format,omitempty"`
+	AddedAt time.Time       `json:"added_at"`
+}
+
+// Store keeps the tracks each user saved, up to MaxFavorites, all of a user's favorites under the user's key.
+type Store struct {
+	cache cacherService
+	now   func() time.Time
+	// mu makes adding, removing and refreshing favorites of a user atomic, they rewrite the whole list.
+	mu sync.Mutex
+}
+
+type userFavorites struct {
+	// Favorites are ordered newest first.
+	Favorites []Favorite `json:"favorites"`
+}
+
+func NewStore(cache cacherService) *Store {
+	return &Store{cache: cache, now: time.Now}
+}
+
+// List returns the user's favorites, newest first.
+func (s *Store) List(ctx context.Context, user string) ([]Favorite, error) {
+	doc, err := s.load(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Favorites, nil
+}
+
+func (s *Store) Get(ctx context.Context, user string, videoID string) (Favorite, error) {
+	doc, err := s.load(ctx, user)
+	if err != nil {
+		return Favorite{}, err
+	}
+	idx := doc.index(videoID)
+	if idx < 0 {
+		return Favorite{}, ErrNotFound
+	}
+	return doc.Favorites[idx], nil
+}
+
+// Add saves the track on top of the user's favorites.
+func (s *Store) Add(ctx context.Context, user string, favorite Favorite) error {
+	favorite.VideoID = strings.TrimSpace(favorite.VideoID)
+	if favorite.VideoID == "" {
+		return errors.New("video id is empty")
+	}
+	if favorite.Title == "" {
+		favorite.Title = favorite.VideoID
+	}
+	favorite.AddedAt = s.now().UTC()
+
+	return s.update(ctx, user, func(doc *userFavorites) error {
+		if doc.index(favorite.VideoID) >= 0 {
+			return ErrExists
+		}
+		if len(doc.Favorites) >= MaxFavorites {
+			return ErrLimitReached
+		}
+		doc.Favorites = append([]Favorite{favorite}, doc.Favorites...)
+		return nil
+	})
+}
+
+func (s *Store) Remove(ctx context.Context, user string, videoID string) error {
+	return s.update(ctx, user, func(doc *userFavorites) error {
+		idx := doc.index(videoID)
+		if idx < 0 {
+			return ErrNotFound
+		}
+		doc.Favorites = append(doc.Favorites[:idx], doc.Favorites[idx+1:]...)
+		return nil
+	})
+}
+
+// SetFile replaces the Telegram file the favorite is resent from, for instance after Telegram lost the old one.
+func (s *Store) SetFile(ctx context.Context, user string, videoID string, fileID string, format settings.Format) error {
+	return s.update(ctx, user, func(doc *userFavorites) error {
+		idx := doc.index(videoID)
+		if idx < 0 {
+			return ErrNotFound
+		}
+		doc.Favorites[idx].FileID = fileID
+		doc.Favorites[idx].Format = format
+		return nil
+	})
+}
+
+func (s *Store) update(ctx context.Context, user string, fn func(doc *userFavorites) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load(ctx, user)
+	if err != nil {
+		return err
+	}
+	if err := fn(&doc); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, user, string(value))
+}
+
+func (s *Store) load(ctx context.Context, user string) (userFavorites, error) {
+	if user == "" {
+		return userFavorites{}, errors.New("user is empty")
+	}
+
+	value, ok, err := s.cache.Get(ctx, user)
+	if err != nil {
+		return userFavorites{}, err
+	}
+	if !ok || value == "" {
+		return userFavorites{}, nil
+	}
+
+	var doc userFavorites
+	if err := json.Unmarshal([]byte(value), &doc); err != nil {
+		return userFavorites{}, err
+	}
+	return doc, nil
+}
+
+func (d userFavorites) index(videoID string) int {
+	for i, favorite := range d.Favorites {
+		if favorite.VideoID == videoID {
+			return i
+		}
+	}
+	return -1
+}
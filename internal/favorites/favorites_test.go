@@ -0,0 +1,103 @@
+package favorites
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/settings"
+)
+
+func memoryStore() *Store {
+	return NewStore(cacher.NewMemory(cacher.FavoritesDB, 0, 0))
+}
+
+func TestAddSavesOnTop(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore()
+	store.now = func() time.Time { return time.Unix(1000, 0) }
+
+	for _, id := range []string{"a", "b"} {
+		if err := store.Add(ctx, "user", Favorite{VideoID: id, Title: "Song " + id, FileID: "file-" + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Add(ctx, "user", Favorite{VideoID: "a"}); !errors.Is(err, ErrExists) {
+		t.Fatalf("adding a saved track err = %v", err)
+	}
+
+	list, err := store.List(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].VideoID != "b" || list[1].VideoID != "a" {
+		t.Fatalf("favorites = %+v, want newest first", list)
+	}
+	if !list[0].AddedAt.Equal(time.Unix(1000, 0)) {
+		t.Fatalf("added at not recorded: %+v", list)
+	}
+
+	favorite, err := store.Get(ctx, "user", "a")
+	if err != nil || favorite.Title != "Song a" || favorite.FileID != "file-a" {
+		t.Fatalf("Get = %+v, %v", favorite, err)
+	}
+	if _, err := store.Get(ctx, "other", "a"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of another user's favorite err = %v", err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore()
+
+	for _, id := range []string{"a", "b", "c"} {
+		_ = store.Add(ctx, "user", Favorite{VideoID: id})
+	}
+	if err := store.Remove(ctx, "user", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Remove(ctx, "user", "b"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("removing twice err = %v", err)
+	}
+
+	list, _ := store.List(ctx, "user")
+	if len(list) != 2 || list[0].VideoID != "c" || list[1].VideoID != "a" {
+		t.Fatalf("favorites = %+v", list)
+	}
+	if list[1].Title != "a" {
+		t.Fatalf("title = %q, want the video ID when none is given", list[1].Title)
+	}
+}
+
+func TestAddLimit(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore()
+
+	for i := 0; i < MaxFavorites; i++ {
+		if err := store.Add(ctx, "user", Favorite{VideoID: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Add(ctx, "user", Favorite{VideoID: "over"}); !errors.Is(err, ErrLimitReached) {
+		t.Fatalf("adding past the limit err = %v", err)
+	}
+}
+
+func TestSetFile(t *testing.T) {
+	ctx := context.Background()
+	store := memoryStore()
+
+	_ = store.Add(ctx, "user", Favorite{VideoID: "a", FileID: "old", Format: settings.FormatAudio})
+	if err := store.SetFile(ctx, "user", "a", "new", settings.FormatFile); err != nil {
+		t.Fatal(err)
+	}
+	if favorite, _ := store.Get(ctx, "user", "a"); favorite.FileID != "new" || favorite.Format != settings.FormatFile {
+		t.Fatalf("favorite = %+v, want the new file and its format", favorite)
+	}
+	if err := store.SetFile(ctx, "user", "b", "new", settings.FormatFile); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("SetFile of a removed favorite err = %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+)
+
+const helpCommand = "help"
+
+// Scope decides in which command menus a command is listed.
+type Scope int
+
+const (
+	// ScopeDefault commands are listed and accepted in every chat.
+	ScopeDefault Scope = iota
+	// ScopePrivate commands are listed in private chats; elsewhere the bot points to the private chat.
+	ScopePrivate
+	// ScopeGroupAdmin commands are listed for group administrators only; handlers check permissions themselves.
+	ScopeGroupAdmin
+)
+
+// Command is a bot command and the handler answering it.
+type Command struct {
+	Name string
+	// Aliases are accepted like Name but not listed in menus.
+	Aliases []string
+	// Usage is the argument syntax shown by /help, such as "<query>".
+	Usage       i18n.Key
+	Description i18n.Key
+	Scope       Scope
+	Handler     handlers.Response
+}
+
+// LocalizerFunc picks the language to answer an update in.
+type LocalizerFunc func(ctx *ext.Context) i18n.Localizer
+
+// Registry holds the bot's commands; it routes them to their handlers, publishes the command menus and
+// answers /help.
+type Registry struct {
+	ctx      context.Context
+	localize LocalizerFunc
+	commands []Command
+}
+
+// NewRegistry registers the commands followed by /help; localize may be nil to answer in the default language.
+func NewRegistry(ctx context.Context, localize LocalizerFunc, commands ...Command) *Registry {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if localize == nil {
+		localize = func(*ext.Context) i18n.Localizer { return i18n.New(i18n.Default) }
+	}
+	r := &Registry{ctx: ctx, localize: localize}
+	r.commands = append(append(r.commands, commands...), Command{
+		Name:        helpCommand,
+		Description: i18n.CommandHelp,
+		Handler:     r.help,
+	})
+	return r
+}
+
+// Commands returns the registered commands in order.
+func (r *Registry) Commands() []Command {
+	return append([]Command(nil), r.commands...)
+}
+
+// Handlers returns a command handler per name and alias. They must be added before message handlers,
+// which would otherwise take commands for text.
+func (r *Registry) Handlers() []ext.Handler {
+	var out []ext.Handler
+	for _, command := range r.commands {
+		response := r.response(command)
+		out = append(out, handlers.NewCommand(command.Name, response))
+		for _, alias := range command.Aliases {
+			out = append(out, handlers.NewCommand(alias, response))
+		}
+	}
+	return out
+}
+
+// SetMyCommands publishes the command menus for the default, private chat and group administrator scopes in
+// every language. The default language's menus go without a language code, for users of any other language.
+func (r *Registry) SetMyCommands(ctx context.Context, b *gotgbot.Bot) error {
+	if b == nil {
+		return errors.New("bot is nil")
+	}
+	menus := []struct {
+		scope  gotgbot.BotCommandScope
+		scopes []Scope
+	}{
+		{gotgbot.BotCommandScopeDefault{}, []Scope{ScopeDefault}},
+		{gotgbot.BotCommandScopeAllPrivateChats{}, []Scope{ScopeDefault, ScopePrivate}},
+		{gotgbot.BotCommandScopeAllChatAdministrators{}, []Scope{ScopeDefault, ScopeGroupAdmin}},
+	}
+	codes := []string{""}
+	for _, lang := range i18n.Languages() {
+		if lang != i18n.Default {
+			codes = append(codes, string(lang))
+		}
+	}
+	for _, code := range codes {
+		tr := i18n.New(i18n.Match(code))
+		for _, menu := range menus {
+			if _, err := b.SetMyCommandsWithContext(ctx, r.menu(tr, menu.scopes...), &gotgbot.SetMyCommandsOpts{
+				Scope:        menu.scope,
+				LanguageCode: code,
+			}); err != nil {
+				return fmt.Errorf("set commands scope=%s language=%s: %w", menu.scope.GetType(), code, err)
+			}
+		}
+	}
+	return nil
+}
+
+// menu lists the commands of the scopes. Telegram shows the most specific scope's menu only, so the narrower
+// menus repeat the default commands.
+func (r *Registry) menu(tr i18n.Localizer, scopes ...Scope) []gotgbot.BotCommand {
+	out := make([]gotgbot.BotCommand, 0, len(r.commands))
+	for _, command := range r.commands {
+		if command.in(scopes...) {
+			out = append(out, gotgbot.BotCommand{Command: command.Name, Description: tr.T(command.Description)})
+		}
+	}
+	return out
+}
+
+// Help lists the commands available in a private chat or, if private is false, in a group.
+func (r *Registry) Help(tr i18n.Localizer, private bool) string {
+	scopes := []Scope{ScopeDefault, ScopeGroupAdmin}
+	if private {
+		scopes = []Scope{ScopeDefault, ScopePrivate}
+	}
+
+	var text strings.Builder
+	text.WriteString(tr.T(i18n.HelpTitle))
+	for _, command := range r.commands {
+		if !command.in(scopes...) {
+			continue
+		}
+		text.WriteString("\n/" + command.Name)
+		if command.Usage != "" {
+			text.WriteString(" " + tr.T(command.Usage))
+		}
+		text.WriteString(" — " + tr.T(command.Description))
+		for _, alias := range command.Aliases {
+			text.WriteString(tr.T(i18n.HelpAlias, alias))
+		}
+	}
+	return text.String()
+}
+
+func (r *Registry) help(b *gotgbot.Bot, ctx *ext.Context) error {
+	if ctx == nil || ctx.EffectiveChat == nil {
+		return errors.New("missing message context")
+	}
+	_, err := b.SendMessageWithContext(r.ctx, ctx.EffectiveChat.Id, r.Help(r.localize(ctx), isPrivate(ctx.EffectiveChat)), nil)
+	return err
+}
+
+// response enforces the scope of private commands before calling the handler, sending a private command
+// used in a group to the private chat.
+func (r *Registry) response(c Command) handlers.Response {
+	if c.Scope != ScopePrivate {
+		return c.Handler
+	}
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if ctx == nil || ctx.EffectiveChat == nil {
+			return nil
+		}
+		if !isPrivate(ctx.EffectiveChat) {
+			_, err := b.SendMessageWithContext(r.ctx, ctx.EffectiveChat.Id, r.localize(ctx).T(i18n.CommandPrivateOnly, c.Name, b.Username), nil)
+			return err
+		}
+		return c.Handler(b, ctx)
+	}
+}
+
+func (c Command) in(scopes ...Scope) bool {
+	for _, scope := range scopes {
+		if c.Scope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func isPrivate(chat *gotgbot.Chat) bool {
+	return chat != nil && chat.Type == gotgbot.ChatTypePrivate
+}
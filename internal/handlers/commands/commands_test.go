@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+
+	"music-bot-v2/internal/i18n"
+)
+
+func noop(*gotgbot.Bot, *ext.Context) error { return nil }
+
+func testRegistry() *Registry {
+	return NewRegistry(context.Background(), nil,
+		Command{Name: "start", Description: i18n.CommandStart, Scope: ScopePrivate, Handler: noop},
+		Command{Name: "search", Aliases: []string{"s"}, Usage: i18n.CommandSearchUsage, Description: i18n.CommandSearch, Handler: noop},
+		Command{Name: "config", Description: "Configure the group", Scope: ScopeGroupAdmin, Handler: noop},
+	)
+}
+
+func menuNames(commands []gotgbot.BotCommand) []string {
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, command.Command)
+	}
+	return names
+}
+
+func TestMenus(t *testing.T) {
+	r := testRegistry()
+
+	tests := []struct {
+		scopes []Scope
+		want   []string
+	}{
+		{[]Scope{ScopeDefault}, []string{"search", "help"}},
+		{[]Scope{ScopeDefault, ScopePrivate}, []string{"start", "search", "help"}},
+		{[]Scope{ScopeDefault, ScopeGroupAdmin}, []string{"search", "config", "help"}},
+	}
+	for _, tt := range tests {
+		if got := menuNames(r.menu(i18n.New(i18n.EN), tt.scopes...)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("menu(%v) = %v, want %v", tt.scopes, got, tt.want)
+		}
+	}
+}
+
+func TestHandlersIncludeAliases(t *testing.T) {
+	// start, search, s, config, help
+	if got := len(testRegistry().Handlers()); got != 5 {
+		t.Fatalf("expected 5 handlers, got %d", got)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	r := testRegistry()
+
+	private := r.Help(i18n.New(i18n.EN), true)
+	for _, want := range []string{"/start — Start the bot", "/search <query> — Search YouTube, also /s", "/help — List commands"} {
+		if !strings.Contains(private, want) {
+			t.Errorf("private help %q lacks %q", private, want)
+		}
+	}
+	if strings.Contains(private, "/config") {
+		t.Errorf("private help lists a group command: %q", private)
+	}
+
+	group := r.Help(i18n.New(i18n.EN), false)
+	if strings.Contains(group, "/start") || !strings.Contains(group, "/config") {
+		t.Errorf("unexpected group help %q", group)
+	}
+
+	if ru := r.Help(i18n.New(i18n.RU), true); !strings.Contains(ru, "/search <запрос> — Искать на YouTube, или /s") {
+		t.Errorf("unexpected russian help %q", ru)
+	}
+}
+
+// sentMessages records the texts of sendMessage calls.
+type sentMessages struct {
+	texts []string
+}
+
+func (s *sentMessages) RequestWithContext(_ context.Context, _ string, method string, params map[string]string, _ map[string]gotgbot.FileReader, _ *gotgbot.RequestOpts) (json.RawMessage, error) {
+	if method == "sendMessage" {
+		s.texts = append(s.texts, params["text"])
+	}
+	return json.RawMessage(`{"message_id":1,"date":0,"chat":{"id":1,"type":"private"}}`), nil
+}
+
+func (s *sentMessages) GetAPIURL(*gotgbot.RequestOpts) string { return "" }
+
+func (s *sentMessages) FileURL(string, string, *gotgbot.RequestOpts) string { return "" }
+
+func TestPrivateCommandInGroup(t *testing.T) {
+	called := false
+	r := NewRegistry(context.Background(), nil, Command{
+		Name:        "start",
+		Description: i18n.CommandStart,
+		Scope:       ScopePrivate,
+		Handler: func(*gotgbot.Bot, *ext.Context) error {
+			called = true
+			return nil
+		},
+	})
+	sent := &sentMessages{}
+	b := &gotgbot.Bot{User: gotgbot.User{Username: "music_bot"}, BotClient: sent}
+	response := r.response(r.Commands()[0])
+
+	group := &ext.Context{EffectiveChat: &gotgbot.Chat{Id: -100, Type: gotgbot.ChatTypeSupergroup}}
+	if err := response(b, group); err != nil {
+		t.Fatal(err)
+	}
+	if called || len(sent.texts) != 1 || sent.texts[0] != "/start works in a private chat with @music_bot." {
+		t.Fatalf("group: handler called %t, sent %q", called, sent.texts)
+	}
+
+	private := &ext.Context{EffectiveChat: &gotgbot.Chat{Id: 1, Type: gotgbot.ChatTypePrivate}}
+	if err := response(b, private); err != nil {
+		t.Fatal(err)
+	}
+	if !called || len(sent.texts) != 1 {
+		t.Fatalf("private: handler called %t, sent %q", called, sent.texts)
+	}
+}
@@ -0,0 +1,66 @@
+package youtube
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	// conversionLockTTL covers resolving the link, downloading and uploading a track.
+	conversionLockTTL = 6 * time.Minute
+	// conversionWait is how long to wait for another replica's file_id before converting anyway.
+	conversionWait = 2 * time.Minute
+	conversionPoll = time.Second
+)
+
+type conversionLocker interface {
+	TryLock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error)
+}
+
+// WithConversionLock coalesces conversions of a track across replicas sharing the lock.
+func WithConversionLock(locker conversionLocker) Option {
+	return func(h *Handler) {
+		h.conversionLock = locker
+	}
+}
+
+// lockConversion takes the cross-replica conversion lock of the track's audio cache key. While another replica
+// holds it, it polls the audio cache for that replica's file_id, which is returned instead of a release function.
+// Without a lock, or when waiting fails, both results are empty and the caller converts unlocked.
+func (h *Handler) lockConversion(ctx context.Context, audioKey string) (func(), string) {
+	if h.conversionLock == nil {
+		return nil, ""
+	}
+
+	key := "convert#" + audioKey
+	deadline := time.Now().Add(conversionWait)
+	for {
+		release, ok, err := h.conversionLock.TryLock(ctx, key, conversionLockTTL)
+		if err != nil {
+			log.Printf("conversion lock key=%s err=%v", audioKey, err)
+			return nil, ""
+		}
+		if ok {
+			// The previous holder may have finished between our cache check and the lock.
+			if fileID := h.getAudioFileID(audioKey); fileID != "" {
+				release()
+				return nil, fileID
+			}
+			return release, ""
+		}
+		if fileID := h.getAudioFileID(audioKey); fileID != "" {
+			return nil, fileID
+		}
+		if time.Now().After(deadline) {
+			log.Printf("conversion lock key=%s wait timed out", audioKey)
+			return nil, ""
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ""
+		case <-time.After(conversionPoll):
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/queue"
+	"music-bot-v2/internal/ratelimit"
+	"music-bot-v2/internal/settings"
+)
+
+const (
+	deliveryCancelCallbackPrefix = "ytx:"
+
+	// chatActionInterval renews the chat action before Telegram hides it after 5 seconds.
+	chatActionInterval = 4 * time.Second
+	// queueFullRetry is how long a bulk delivery waits before submitting its next track again to a full queue.
+	queueFullRetry = 5 * time.Second
+)
+
+type deliveryQueue interface {
+	Submit(job queue.Job) (int, error)
+	Cancel(key string) bool
+}
+
+// WithQueue runs track deliveries on a worker pool, reporting their progress in a status message.
+// Without it tracks are delivered while the update is handled.
+func WithQueue(q deliveryQueue) Option {
+	return func(h *Handler) {
+		h.queue = q
+	}
+}
+
+// enqueueTrack posts a status message for the track and queues its delivery; only the requester may cancel it.
+// delivered, if set, is called once the track is delivered.
+func (h *Handler) enqueueTrack(tr i18n.Localizer, prefs settings.Settings, b *gotgbot.Bot, chatID int64, requester string, trackID string, delivered func(prefs settings.Settings)) error {
+	message, err := b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.DeliveryQueued), &gotgbot.SendMessageOpts{
+		DisableNotification: true,
+		ReplyMarkup:         deliveryCancelKeyboard(tr, requester),
+	})
+	if err != nil {
+		return err
+	}
+
+	status := &deliveryStatus{ctx: h.ctx, b: b, tr: tr, chatID: chatID, messageID: message.MessageId, owner: requester}
+	position, err := h.queue.Submit(queue.Job{
+		Key: messageKey(chatID, message.MessageId),
+		Run: func(ctx context.Context) {
+			h.runDelivery(ctx, b, status, trackID, prefs, delivered)
+		},
+		Moved: status.queued,
+	})
+	if errors.Is(err, queue.ErrFull) {
+		status.finish(i18n.DeliveryQueueFull)
+		return nil
+	}
+	if err != nil {
+		status.finish(i18n.TrackFailed)
+		return err
+	}
+	status.queued(position)
+	return nil
+}
+
+// runDelivery is a queue job: it delivers the track, showing the chat action while it works, and
+// removes the status message once the audio is sent.
+func (h *Handler) runDelivery(ctx context.Context, b *gotgbot.Bot, status *deliveryStatus, trackID string, prefs settings.Settings, delivered func(prefs settings.Settings)) {
+	stopAction := keepChatAction(ctx, b, status.chatID, gotgbot.ChatActionUploadVoice)
+	err := h.sendTrack(ctx, status.tr, b, status.chatID, trackID, prefs, status.stage)
+	stopAction()
+
+	switch {
+	case err == nil:
+		status.remove()
+		if delivered != nil {
+			delivered(prefs)
+		}
+	case ctx.Err() != nil && h.ctx.Err() == nil:
+		status.finish(i18n.DeliveryCancelled)
+	default:
+		log.Printf("queued delivery track_id=%s err=%v", trackID, err)
+		status.finish(i18n.TrackFailed)
+	}
+}
+
+func (h *Handler) deliveryCancelCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveMessage == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		owner := strings.TrimPrefix(ctx.CallbackQuery.Data, deliveryCancelCallbackPrefix)
+		if owner != requesterID(ctx) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.DeliveryNotOwner))
+		}
+		chatID := ctx.EffectiveMessage.Chat.Id
+		messageID := ctx.EffectiveMessage.MessageId
+		if h.queue == nil || !h.queue.Cancel(messageKey(chatID, messageID)) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.DeliveryNothingToStop))
+		}
+		// A waiting job never runs, so the status is finished here; a running one reports the same.
+		status := &deliveryStatus{ctx: h.ctx, b: b, tr: tr, chatID: chatID, messageID: messageID, owner: owner}
+		status.finish(i18n.DeliveryCancelled)
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.DeliveryCancelled))
+	}
+}
+
+// deliveryStatus edits the status message of a queued delivery. Position updates arrive asynchronously,
+// so they are dropped once the job has reached a later stage. owner is the requester, the only user its
+// cancel button accepts.
+type deliveryStatus struct {
+	ctx       context.Context
+	b         *gotgbot.Bot
+	tr        i18n.Localizer
+	chatID    int64
+	messageID int64
+	owner     string
+
+	mu      sync.Mutex
+	started bool
+	done    bool
+}
+
+func (s *deliveryStatus) queued(position int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.done {
+		return
+	}
+	text := s.tr.T(i18n.DeliveryQueued)
+	if position > 1 {
+		text = s.tr.T(i18n.DeliveryQueuedPosition, position)
+	}
+	s.edit(text, deliveryCancelKeyboard(s.tr, s.owner))
+}
+
+func (s *deliveryStatus) stage(stage i18n.Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.started = true
+	s.edit(s.tr.T(stage), deliveryCancelKeyboard(s.tr, s.owner))
+}
+
+func (s *deliveryStatus) finish(key i18n.Key) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return
+	}
+	s.done = true
+	s.edit(s.tr.T(key), gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{}})
+}
+
+func (s *deliveryStatus) remove() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	_, _ = s.b.DeleteMessageWithContext(s.ctx, s.chatID, s.messageID, nil)
+}
+
+func (s *deliveryStatus) edit(text string, keyboard gotgbot.InlineKeyboardMarkup) {
+	_, _, _ = s.b.EditMessageTextWithContext(s.ctx, text, &gotgbot.EditMessageTextOpts{
+		ChatId:      s.chatID,
+		MessageId:   s.messageID,
+		ReplyMarkup: keyboard,
+	})
+}
+
+func deliveryCancelKeyboard(tr i18n.Localizer, owner string) gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+		Text:         tr.T(i18n.ButtonCancel),
+		CallbackData: deliveryCancelCallbackPrefix + owner,
+	}}}}
+}
+
+// deliverTracks sends the tracks in order for the requester, each through the delivery queue like a single
+// track, so bulk deliveries share the workers instead of converting on their own. Every track after the first
+// spends a download token, waiting for the bucket to refill; the caller spends the first one when it accepts
+// the request. progress, if set, is called before each track. Delivery stops when ctx is done.
+func (h *Handler) deliverTracks(ctx context.Context, tr i18n.Localizer, prefs settings.Settings, b *gotgbot.Bot, chatID int64, requester string, trackIDs []string, progress func(i int)) (sent int, failed int) {
+	for i, trackID := range trackIDs {
+		if i > 0 {
+			if err := h.waitAllow(ctx, ratelimit.Download, requester); err != nil {
+				break
+			}
+		}
+		if progress != nil {
+			progress(i)
+		}
+		err := h.runQueued(ctx, func(ctx context.Context) error {
+			return h.sendTrack(ctx, tr, b, chatID, trackID, prefs, nil)
+		})
+		if ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			failed++
+			log.Printf("bulk delivery track_id=%s err=%v", trackID, err)
+			continue
+		}
+		sent++
+	}
+	return sent, failed
+}
+
+// runQueued runs the delivery on a queue worker and waits for it, retrying while the queue is full. The
+// delivery is aborted when ctx is done. Without a queue it runs right away.
+func (h *Handler) runQueued(ctx context.Context, deliver func(ctx context.Context) error) error {
+	if h.queue == nil {
+		return deliver(ctx)
+	}
+
+	key := "bulk#" + strconv.FormatUint(h.bulkJobs.Add(1), 10)
+	done := make(chan error, 1)
+	job := queue.Job{
+		Key: key,
+		Run: func(jobCtx context.Context) {
+			jobCtx, cancel := context.WithCancel(jobCtx)
+			defer cancel()
+			defer context.AfterFunc(ctx, cancel)()
+			done <- deliver(jobCtx)
+		},
+	}
+	for {
+		_, err := h.queue.Submit(job)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, queue.ErrFull) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(queueFullRetry):
+		}
+	}
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		h.queue.Cancel(key)
+		return ctx.Err()
+	}
+}
+
+// keepChatAction shows the chat action until ctx is done or the returned function is called.
+func keepChatAction(ctx context.Context, b *gotgbot.Bot, chatID int64, action string) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(chatActionInterval)
+		defer ticker.Stop()
+		for {
+			_, _ = b.SendChatActionWithContext(ctx, chatID, action, nil)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,325 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/favorites"
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/ratelimit"
+	"music-bot-v2/internal/settings"
+)
+
+const (
+	favoritesCommand = "favorites"
+
+	favoriteSaveCallbackPrefix   = "fav:"
+	favoriteOpenCallbackPrefix   = "fvo:"
+	favoriteSendCallbackPrefix   = "fvs:"
+	favoriteRemoveCallbackPrefix = "fvr:"
+)
+
+type favoritesStore interface {
+	List(ctx context.Context, user string) ([]favorites.Favorite, error)
+	Get(ctx context.Context, user string, videoID string) (favorites.Favorite, error)
+	Add(ctx context.Context, user string, favorite favorites.Favorite) error
+	Remove(ctx context.Context, user string, videoID string) error
+	SetFile(ctx context.Context, user string, videoID string, fileID string, format settings.Format) error
+}
+
+// WithFavorites keeps the tracks users save with the button on delivered audio.
+func WithFavorites(store favoritesStore) Option {
+	return func(h *Handler) {
+		h.favorites = store
+	}
+}
+
+func saveFavoriteKeyboard(tr i18n.Localizer, trackID string) gotgbot.InlineKeyboardMarkup {
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+		Text:         tr.T(i18n.FavoritesSave),
+		CallbackData: favoriteSaveCallbackPrefix + trackID,
+	}}}}
+}
+
+// favoriteSaveCallback handles ❤️ under delivered audio, saving the track with the file_id of the message and the
+// format it was presented in.
+func (h *Handler) favoriteSaveCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.music == nil {
+			return errors.New("music consumer is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil {
+			return errors.New("missing callback query")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, favoriteSaveCallbackPrefix, 1)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidTrack))
+			return err
+		}
+		if h.favorites == nil {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesFailed))
+		}
+
+		trackID := args[0]
+		favorite := favorites.Favorite{
+			VideoID: trackID,
+			Title:   h.favoriteTitle(ctx.EffectiveMessage, trackID),
+			FileID:  sentFileID(ctx.EffectiveMessage),
+			Format:  sentFormat(ctx.EffectiveMessage),
+		}
+		err = h.favorites.Add(h.ctx, requesterID(ctx), favorite)
+		switch {
+		case err == nil:
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesSaved))
+		case errors.Is(err, favorites.ErrExists):
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesExists))
+		case errors.Is(err, favorites.ErrLimitReached):
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesLimit, favorites.MaxFavorites))
+		}
+		_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesFailed))
+		return err
+	}
+}
+
+// favoriteTitle names a saved track after the tags of the audio message, looking the video up for files.
+func (h *Handler) favoriteTitle(message *gotgbot.Message, trackID string) string {
+	if message != nil && message.Audio != nil && message.Audio.Title != "" {
+		if message.Audio.Performer != "" {
+			return message.Audio.Performer + " - " + message.Audio.Title
+		}
+		return message.Audio.Title
+	}
+	info, err := h.music.Video(h.ctx, trackID)
+	if err != nil {
+		log.Printf("favorite title track_id=%s err=%v", trackID, err)
+		return trackID
+	}
+	return info.Label()
+}
+
+func (h *Handler) favoritesCommand() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing message context")
+		}
+
+		tr := h.localizer(ctx)
+		chatID := ctx.EffectiveChat.Id
+		if h.favorites == nil {
+			_, err := b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.FavoritesEmpty), nil)
+			return err
+		}
+
+		list, err := h.favorites.List(h.ctx, requesterID(ctx))
+		if err != nil {
+			_, _ = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.FavoritesFailed), nil)
+			return err
+		}
+		if len(list) == 0 {
+			_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.FavoritesEmpty), nil)
+			return err
+		}
+
+		text, keyboard := buildFavoritesView(tr, list, 0, h.userSettings(ctx).PageSize)
+		_, err = b.SendMessageWithContext(h.ctx, chatID, text, &gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+		return err
+	}
+}
+
+// favoriteOpenCallback turns the page of the favorites message.
+func (h *Handler) favoriteOpenCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.favorites == nil {
+			return errors.New("favorites store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil {
+			return errors.New("missing callback query")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, favoriteOpenCallbackPrefix, 1)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPage))
+			return err
+		}
+		page, err := strconv.Atoi(args[0])
+		if err != nil || page < 0 {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPage))
+			return errors.New("invalid page")
+		}
+		return h.showFavorites(b, ctx, tr, page)
+	}
+}
+
+// favoriteSendCallback resends a saved track by its file_id in the format it was saved in, converting it again
+// only when Telegram no longer accepts the file. Only a conversion costs a download, the new file replaces the saved
+// one.
+func (h *Handler) favoriteSendCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.favorites == nil || h.music == nil {
+			return errors.New("favorites store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, favoriteSendCallbackPrefix, 1)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidTrack))
+			return err
+		}
+
+		user := requesterID(ctx)
+		favorite, err := h.favorites.Get(h.ctx, user, args[0])
+		if errors.Is(err, favorites.ErrNotFound) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesNotFound))
+		}
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesFailed))
+			return err
+		}
+
+		if favorite.FileID != "" {
+			// Favorites saved without a format were mostly sent as audio, a document is converted again once.
+			format := favorite.Format
+			if format == "" {
+				format = settings.FormatAudio
+			}
+			saved := audioTags{markup: saveFavoriteKeyboard(tr, favorite.VideoID)}
+			_, err := saved.send(h.ctx, b, ctx.EffectiveChat.Id, gotgbot.InputFileByID(favorite.FileID), format, nil)
+			if err == nil {
+				return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+			}
+			log.Printf("favorite resend track_id=%s err=%v, delivering again", favorite.VideoID, err)
+		}
+
+		if text, ok := h.allow(tr, ratelimit.Download, user); !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+		}
+		return h.deliverTrack(b, ctx, tr, favorite.VideoID, func(prefs settings.Settings) {
+			h.refreshFavorite(user, favorite.VideoID, prefs)
+		})
+	}
+}
+
+// refreshFavorite points the favorite at the file a delivery with prefs has just sent, so it is resent without a
+// conversion next time.
+func (h *Handler) refreshFavorite(user string, videoID string, prefs settings.Settings) {
+	fileID := h.getAudioFileID(audioCacheKey(videoID, prefs))
+	if fileID == "" {
+		return
+	}
+	err := h.favorites.SetFile(h.ctx, user, videoID, fileID, prefs.Format)
+	if err != nil && !errors.Is(err, favorites.ErrNotFound) {
+		log.Printf("favorite refresh track_id=%s err=%v", videoID, err)
+	}
+}
+
+func (h *Handler) favoriteRemoveCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.favorites == nil {
+			return errors.New("favorites store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil {
+			return errors.New("missing callback query")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, favoriteRemoveCallbackPrefix, 2)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidTrack))
+			return err
+		}
+		page, _ := strconv.Atoi(args[0])
+
+		// A track removed from another message is gone already, the page is redrawn either way.
+		err = h.favorites.Remove(h.ctx, requesterID(ctx), args[1])
+		if err != nil && !errors.Is(err, favorites.ErrNotFound) {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesFailed))
+			return err
+		}
+		return h.showFavorites(b, ctx, tr, page)
+	}
+}
+
+// showFavorites redraws the favorites message at the page.
+func (h *Handler) showFavorites(b *gotgbot.Bot, ctx *ext.Context, tr i18n.Localizer, page int) error {
+	list, err := h.favorites.List(h.ctx, requesterID(ctx))
+	if err != nil {
+		_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.FavoritesFailed))
+		return err
+	}
+	if len(list) == 0 {
+		noKeyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{}}
+		return h.editPlaylistMessage(b, ctx, tr.T(i18n.FavoritesEmpty), noKeyboard)
+	}
+
+	text, keyboard := buildFavoritesView(tr, list, page, h.userSettings(ctx).PageSize)
+	return h.editPlaylistMessage(b, ctx, text, keyboard)
+}
+
+func buildFavoritesView(tr i18n.Localizer, list []favorites.Favorite, page int, limit int) (string, gotgbot.InlineKeyboardMarkup) {
+	if limit <= 0 {
+		limit = playlistPageLimit
+	}
+	totalPages := pageCount(len(list), limit)
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	start := page * limit
+	end := min(start+limit, len(list))
+
+	rows := make([][]gotgbot.InlineKeyboardButton, 0, end-start+1)
+	for i, favorite := range list[start:end] {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         trimButtonLabel(fmt.Sprintf("%d. %s", start+i+1, favorite.Title)),
+				CallbackData: favoriteSendCallbackPrefix + favorite.VideoID,
+			},
+			{
+				Text:         "❌",
+				CallbackData: fmt.Sprintf("%s%d:%s", favoriteRemoveCallbackPrefix, page, favorite.VideoID),
+			},
+		})
+	}
+
+	if totalPages > 1 {
+		nav := make([]gotgbot.InlineKeyboardButton, 0, 2)
+		if page > 0 {
+			nav = append(nav, gotgbot.InlineKeyboardButton{
+				Text:         "⬅️",
+				CallbackData: favoriteOpenCallbackPrefix + strconv.Itoa(page-1),
+			})
+		}
+		if page+1 < totalPages {
+			nav = append(nav, gotgbot.InlineKeyboardButton{
+				Text:         "➡️",
+				CallbackData: favoriteOpenCallbackPrefix + strconv.Itoa(page+1),
+			})
+		}
+		rows = append(rows, nav)
+	}
+
+	text := tr.N(i18n.FavoritesHeader, len(list), len(list))
+	if totalPages > 1 {
+		text += tr.T(i18n.FavoritesHeaderPage, page+1, totalPages)
+	}
+	return text + ":", gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
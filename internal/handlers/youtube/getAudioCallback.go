@@ -1,14 +1,30 @@
 package youtube
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
+	"log"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/id3"
+	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/ratelimit"
+	"music-bot-v2/internal/settings"
+	youtubeapi "music-bot-v2/internal/youtube"
 )
 
+// uploadTimeout bounds a multipart upload of up to 50 MB to Telegram.
+const uploadTimeout = 5 * time.Minute
+
 func (h *Handler) getAudioCallback() handlers.Response {
 	return func(b *gotgbot.Bot, ctx *ext.Context) error {
 		if h == nil || h.music == nil {
@@ -18,40 +34,351 @@ func (h *Handler) getAudioCallback() handlers.Response {
 			return errors.New("missing callback query context")
 		}
 
+		tr := h.localizer(ctx)
 		trackID, err := parseTrackID(ctx.CallbackQuery.Data)
 		if err != nil {
-			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, "Invalid track.")
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidTrack))
 			return err
 		}
+		if text, ok := h.allow(tr, ratelimit.Download, requesterID(ctx)); !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+		}
+		return h.deliverTrack(b, ctx, tr, trackID, nil)
+	}
+}
+
+// deliverTrack sends the track in answer to a callback, through the delivery queue when there is one.
+// delivered, if set, is called with the settings the track was sent with once it is delivered.
+func (h *Handler) deliverTrack(b *gotgbot.Bot, ctx *ext.Context, tr i18n.Localizer, trackID string, delivered func(prefs settings.Settings)) error {
+	prefs := h.userSettings(ctx)
+	if h.queue == nil {
+		if err := h.sendTrack(h.ctx, tr, b, ctx.EffectiveChat.Id, trackID, prefs, nil); err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.TrackFailed))
+			return err
+		}
+		if delivered != nil {
+			delivered(prefs)
+		}
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	}
+
+	// Telegram waits for the answer only briefly, a conversion takes longer.
+	_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.DeliveryQueued))
+	return h.enqueueTrack(tr, prefs, b, ctx.EffectiveChat.Id, requesterID(ctx), trackID, delivered)
+}
 
-		fileID := h.getAudioFileID(trackID)
-		if fileID != "" {
-			_, err = b.SendAudioWithContext(h.ctx, ctx.EffectiveChat.Id, gotgbot.InputFileByID(fileID), nil)
-			if err == nil {
-				return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+// stageFunc reports the delivery stage of a track, such as converting or uploading.
+type stageFunc func(stage i18n.Key)
+
+func (f stageFunc) report(stage i18n.Key) {
+	if f != nil {
+		f(stage)
+	}
+}
+
+// sendTrack delivers the track's audio to the chat in the format of the user's settings, reusing the cached
+// file_id when Telegram still accepts it. Concurrent deliveries of a track in the same format are coalesced:
+// one caller converts and uploads it, the others send its file_id. A waiter converts the track itself when
+// that caller fails or gets no file_id back, its failure may be specific to its own chat. The audio carries
+// a button saving it to the user's favorites.
+func (h *Handler) sendTrack(ctx context.Context, tr i18n.Localizer, b *gotgbot.Bot, chatID int64, trackID string, prefs settings.Settings, stage stageFunc) error {
+	saved := audioTags{markup: saveFavoriteKeyboard(tr, trackID)}
+	key := audioCacheKey(trackID, prefs)
+	fileID := h.getAudioFileID(key)
+	if fileID != "" {
+		_, err := saved.send(ctx, b, chatID, gotgbot.InputFileByID(fileID), prefs.Format, nil)
+		if err == nil {
+			return nil
+		}
+		var tgErr *gotgbot.TelegramError
+		if errors.As(err, &tgErr) && tgErr.Code == 400 {
+			h.clearAudioFileID(key)
+		}
+	}
+
+	convert := func() (delivery, error) {
+		return h.convertTrack(ctx, tr, b, chatID, trackID, prefs, stage)
+	}
+	for {
+		result, executed, err := h.deliveries.Do(ctx, key, convert)
+		if !executed {
+			if ctx.Err() != nil {
+				return ctx.Err()
 			}
-			var tgErr *gotgbot.TelegramError
-			if errors.As(err, &tgErr) && tgErr.Code == 400 {
-				go h.clearAudioFileID(trackID)
+			// The caller converting the track was cancelled, this one takes over.
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				continue
+			}
+			if err != nil || result.fileID == "" {
+				result, err = convert()
+				executed = true
 			}
 		}
-
-		link, err := h.music.MP3Link(h.ctx, trackID)
 		if err != nil {
-			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, "Failed to load track.")
 			return err
 		}
+		if executed && result.sent {
+			return nil
+		}
+		_, err = saved.send(ctx, b, chatID, gotgbot.InputFileByID(result.fileID), prefs.Format, nil)
+		return err
+	}
+}
+
+// audioCacheKey is the key the track's file_id is cached under. Files and audio without cover art are uploads
+// of their own, the default delivery keeps the bare track ID.
+func audioCacheKey(trackID string, prefs settings.Settings) string {
+	key := trackID
+	if prefs.Format == settings.FormatFile {
+		key += "#file"
+	}
+	if !prefs.Thumbnails {
+		key += "#nocover"
+	}
+	return key
+}
+
+// delivery is the outcome of a conversion shared with coalesced callers; sent reports whether the audio
+// already went to the converting caller's chat.
+type delivery struct {
+	fileID string
+	sent   bool
+}
+
+// convertTrack resolves the MP3 link and sends the audio to the chat, unless another replica is already
+// converting the track and its file_id arrives in time.
+func (h *Handler) convertTrack(ctx context.Context, tr i18n.Localizer, b *gotgbot.Bot, chatID int64, trackID string, prefs settings.Settings, stage stageFunc) (delivery, error) {
+	key := audioCacheKey(trackID, prefs)
+	release, fileID := h.lockConversion(ctx, key)
+	if fileID != "" {
+		return delivery{fileID: fileID}, nil
+	}
+	if release != nil {
+		defer release()
+	}
+
+	stage.report(i18n.StageConverting)
+	link, err := h.music.MP3Link(ctx, trackID)
+	if err != nil {
+		return delivery{}, err
+	}
 
-		message, err := b.SendAudioWithContext(h.ctx, ctx.EffectiveChat.Id, gotgbot.InputFileByURL(link), nil)
+	tags := h.audioTags(ctx, trackID, prefs.Thumbnails)
+	tags.markup = saveFavoriteKeyboard(tr, trackID)
+	var message *gotgbot.Message
+	if h.downloader != nil {
+		// Uploading from here allows retagging the file and sending up to 50 MB, Telegram fetches URLs only up to 20 MB.
+		message, err = h.uploadAudio(ctx, b, chatID, trackID, link, tags, prefs.Format, stage)
 		if err != nil {
-			return err
+			if ctx.Err() != nil {
+				return delivery{}, ctx.Err()
+			}
+			log.Printf("upload audio track_id=%s err=%v, sending by url", trackID, err)
 		}
-		if message != nil && message.Audio != nil && message.Audio.FileId != "" {
-			go h.setAudioFileID(trackID, message.Audio.FileId)
+	}
+	if message == nil {
+		stage.report(i18n.StageSending)
+		message, err = tags.send(ctx, b, chatID, gotgbot.InputFileByURL(link), prefs.Format, nil)
+		if err != nil {
+			// The cached link may have expired, the next attempt resolves a fresh one.
+			if linkRejected(err) {
+				h.music.ForgetMP3Link(h.ctx, trackID)
+			}
+			return delivery{}, err
 		}
+	}
 
-		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	result := delivery{sent: true, fileID: sentFileID(message)}
+	if result.fileID != "" {
+		// Stored before the lock is released, replicas waiting for the conversion read it from the cache.
+		h.setAudioFileID(key, result.fileID)
+	}
+	return result, nil
+}
+
+// linkRejected reports whether Telegram failed to fetch the file from its URL, as opposed to failing to
+// deliver it to the chat.
+func linkRejected(err error) bool {
+	var tgErr *gotgbot.TelegramError
+	if !errors.As(err, &tgErr) || tgErr.Code != 400 {
+		return false
+	}
+	description := strings.ToLower(tgErr.Description)
+	return strings.Contains(description, "url") || strings.Contains(description, "web page content")
+}
+
+// sentFormat is how the track of sentFileID was presented, which its file_id has to be resent as.
+func sentFormat(message *gotgbot.Message) settings.Format {
+	if message != nil && message.Document != nil && message.Audio == nil {
+		return settings.FormatFile
 	}
+	return settings.FormatAudio
+}
+
+// sentFileID is the file_id of a sent track, Telegram may present a file it recognizes as music as audio.
+func sentFileID(message *gotgbot.Message) string {
+	switch {
+	case message == nil:
+		return ""
+	case message.Audio != nil:
+		return message.Audio.FileId
+	case message.Document != nil:
+		return message.Document.FileId
+	}
+	return ""
+}
+
+// uploadAudio downloads the converted file, replaces the converter's ID3 tags with the video's metadata and
+// uploads it to Telegram as multipart.
+func (h *Handler) uploadAudio(ctx context.Context, b *gotgbot.Bot, chatID int64, trackID string, link string, tags audioTags, format settings.Format, stage stageFunc) (*gotgbot.Message, error) {
+	stage.report(i18n.StageDownloading)
+	file, err := h.downloader.Fetch(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var audio io.Reader = file
+	if tags.ok {
+		tagged, _, err := id3.Rewrite(file, file.Size, tags.id3Tag(trackID))
+		if err != nil {
+			log.Printf("id3 rewrite track_id=%s err=%v", trackID, err)
+		} else {
+			audio = tagged
+		}
+	}
+
+	stage.report(i18n.StageUploading)
+	upload := gotgbot.InputFileByReader(tags.fileName(trackID), audio)
+	return tags.send(ctx, b, chatID, upload, format, &gotgbot.RequestOpts{Timeout: uploadTimeout})
+}
+
+type trackMetadata struct {
+	title        string
+	performer    string
+	album        string
+	year         int
+	duration     int64
+	thumbnailURL string
+}
+
+// trackMetadata looks up the video to tag the audio; delivery goes on untagged if the lookup fails.
+func (h *Handler) trackMetadata(ctx context.Context, trackID string) (trackMetadata, bool) {
+	info, err := h.music.Video(ctx, trackID)
+	if err != nil {
+		log.Printf("track metadata track_id=%s err=%v", trackID, err)
+		return trackMetadata{}, false
+	}
+	performer, title := music.SplitTitle(info.Title, info.ChannelTitle)
+	return trackMetadata{
+		title:        title,
+		performer:    performer,
+		album:        info.ChannelTitle,
+		year:         info.PublishedAt.Year(),
+		duration:     int64(info.DurationSec),
+		thumbnailURL: info.ThumbnailURL,
+	}, true
+}
+
+// audioTags is the metadata and cover delivered audio is tagged with, ok is false when the lookup failed.
+// markup is the keyboard attached to the sent message.
+type audioTags struct {
+	meta      trackMetadata
+	ok        bool
+	thumbnail []byte
+	markup    gotgbot.ReplyMarkup
+}
+
+// audioTags looks up the track's metadata, and its cover art unless cover is false.
+func (h *Handler) audioTags(ctx context.Context, trackID string, cover bool) audioTags {
+	meta, ok := h.trackMetadata(ctx, trackID)
+	if !ok {
+		return audioTags{}
+	}
+
+	tags := audioTags{meta: meta, ok: true}
+	if cover && meta.thumbnailURL != "" {
+		thumbnail, err := h.music.Thumbnail(ctx, meta.thumbnailURL)
+		if err != nil {
+			log.Printf("track thumbnail track_id=%s err=%v", trackID, err)
+		} else {
+			tags.thumbnail = thumbnail
+		}
+	}
+	return tags
+}
+
+// sendOpts builds new options on every call, a send attempt consumes the thumbnail reader.
+func (t audioTags) sendOpts() *gotgbot.SendAudioOpts {
+	if !t.ok {
+		return nil
+	}
+
+	opts := &gotgbot.SendAudioOpts{
+		Title:     t.meta.title,
+		Performer: t.meta.performer,
+		Duration:  t.meta.duration,
+	}
+	if len(t.thumbnail) > 0 {
+		opts.Thumbnail = gotgbot.InputFileByReader("thumbnail.jpg", bytes.NewReader(t.thumbnail))
+	}
+	return opts
+}
+
+// send sends the file as audio or, in the file format, as a document Telegram does not turn into audio.
+func (t audioTags) send(ctx context.Context, b *gotgbot.Bot, chatID int64, file gotgbot.InputFileOrString, format settings.Format, request *gotgbot.RequestOpts) (*gotgbot.Message, error) {
+	if format == settings.FormatFile {
+		opts := &gotgbot.SendDocumentOpts{
+			DisableContentTypeDetection: true,
+			ReplyMarkup:                 t.markup,
+			RequestOpts:                 request,
+		}
+		if len(t.thumbnail) > 0 {
+			opts.Thumbnail = gotgbot.InputFileByReader("thumbnail.jpg", bytes.NewReader(t.thumbnail))
+		}
+		return b.SendDocumentWithContext(ctx, chatID, file, opts)
+	}
+
+	opts := t.sendOpts()
+	if opts == nil {
+		opts = &gotgbot.SendAudioOpts{}
+	}
+	opts.ReplyMarkup = t.markup
+	opts.RequestOpts = request
+	return b.SendAudioWithContext(ctx, chatID, file, opts)
+}
+
+// id3Tag is written into uploaded files; the channel stands in for the album, which YouTube does not provide.
+func (t audioTags) id3Tag(trackID string) id3.Tag {
+	tag := id3.Tag{
+		Title:   t.meta.title,
+		Artist:  t.meta.performer,
+		Album:   t.meta.album,
+		Comment: youtubeapi.WatchURL(trackID),
+		URL:     youtubeapi.WatchURL(trackID),
+		Cover:   t.thumbnail,
+	}
+	if t.meta.year > 1 {
+		tag.Year = strconv.Itoa(t.meta.year)
+	}
+	return tag
+}
+
+// fileName is the name uploaded audio is saved under by Telegram clients.
+func (t audioTags) fileName(trackID string) string {
+	name := trackID
+	if t.ok && t.meta.title != "" {
+		name = t.meta.title
+		if t.meta.performer != "" {
+			name = t.meta.performer + " - " + name
+		}
+	}
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, name) + ".mp3"
 }
 
 func parseTrackID(data string) (string, error) {
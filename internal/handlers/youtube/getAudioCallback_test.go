@@ -0,0 +1,210 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+
+	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/settings"
+)
+
+const blockedChat = 403
+
+// fakeTelegram answers sendAudio: chat blockedChat has blocked the bot, the others receive audio with a file_id
+// unless withoutFileID is set.
+type fakeTelegram struct {
+	withoutFileID bool
+
+	mu    sync.Mutex
+	sends map[string][]string
+}
+
+func (f *fakeTelegram) RequestWithContext(_ context.Context, _ string, method string, params map[string]string, _ map[string]gotgbot.FileReader, _ *gotgbot.RequestOpts) (json.RawMessage, error) {
+	if method != "sendAudio" {
+		return nil, fmt.Errorf("unexpected method %s", method)
+	}
+	chatID := params["chat_id"]
+	if chatID == fmt.Sprint(blockedChat) {
+		return nil, &gotgbot.TelegramError{Method: method, Code: 403, Description: "Forbidden: bot was blocked by the user"}
+	}
+
+	f.mu.Lock()
+	if f.sends == nil {
+		f.sends = make(map[string][]string)
+	}
+	f.sends[chatID] = append(f.sends[chatID], params["audio"])
+	f.mu.Unlock()
+
+	audio := ""
+	if !f.withoutFileID {
+		audio = `,"audio":{"file_id":"file-id","file_unique_id":"unique","duration":1}`
+	}
+	return json.RawMessage(`{"message_id":1,"date":0,"chat":{"id":` + chatID + `,"type":"private"}` + audio + `}`), nil
+}
+
+func (f *fakeTelegram) GetAPIURL(*gotgbot.RequestOpts) string { return "" }
+
+func (f *fakeTelegram) FileURL(string, string, *gotgbot.RequestOpts) string { return "" }
+
+func (f *fakeTelegram) sent(chatID int64) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sends[fmt.Sprint(chatID)]
+}
+
+// fakeLinks resolves MP3 links, holding the first resolution until release is closed.
+type fakeLinks struct {
+	musicSearcher
+
+	started chan struct{}
+	release chan struct{}
+
+	mu        sync.Mutex
+	resolved  int
+	forgotten int
+}
+
+func newFakeLinks() *fakeLinks {
+	return &fakeLinks{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (f *fakeLinks) MP3Link(context.Context, string) (string, error) {
+	f.mu.Lock()
+	f.resolved++
+	first := f.resolved == 1
+	f.mu.Unlock()
+	if first {
+		close(f.started)
+		<-f.release
+	}
+	return "https://cdn.example/track.mp3", nil
+}
+
+func (f *fakeLinks) ForgetMP3Link(context.Context, string) {
+	f.mu.Lock()
+	f.forgotten++
+	f.mu.Unlock()
+}
+
+func (f *fakeLinks) Video(context.Context, string) (music.VideoInfo, error) {
+	return music.VideoInfo{}, errors.New("no metadata")
+}
+
+// deliverConcurrently sends the track to both chats, the second delivery starting while the first converts.
+func deliverConcurrently(t *testing.T, telegram *fakeTelegram, links *fakeLinks, converter int64, waiter int64) (error, error) {
+	t.Helper()
+	h := &Handler{ctx: context.Background(), music: links, audioCache: cacher.NewMemory(cacher.AudioCacheDB, 0, 0)}
+	b := &gotgbot.Bot{Token: "token", BotClient: telegram}
+	tr := i18n.New(i18n.EN)
+
+	var converterErr, waiterErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		converterErr = h.sendTrack(context.Background(), tr, b, converter, "dQw4w9WgXcQ", settings.Default(), nil)
+	}()
+	<-links.started
+	go func() {
+		defer wg.Done()
+		waiterErr = h.sendTrack(context.Background(), tr, b, waiter, "dQw4w9WgXcQ", settings.Default(), nil)
+	}()
+	// Gives the waiter time to join the conversion in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(links.release)
+	wg.Wait()
+	return converterErr, waiterErr
+}
+
+func TestSendTrackCoalescesConversions(t *testing.T) {
+	telegram := &fakeTelegram{}
+	links := newFakeLinks()
+
+	converterErr, waiterErr := deliverConcurrently(t, telegram, links, 1, 2)
+	if converterErr != nil || waiterErr != nil {
+		t.Fatalf("errors: converter %v, waiter %v", converterErr, waiterErr)
+	}
+	if links.resolved != 1 {
+		t.Fatalf("link resolved %d times, want once", links.resolved)
+	}
+	if got := telegram.sent(2); len(got) != 1 || got[0] != "file-id" {
+		t.Fatalf("waiter chat received %v, want the converted file_id", got)
+	}
+}
+
+func TestSendTrackWaiterConvertsAfterChatFailure(t *testing.T) {
+	telegram := &fakeTelegram{}
+	links := newFakeLinks()
+
+	converterErr, waiterErr := deliverConcurrently(t, telegram, links, blockedChat, 2)
+	if converterErr == nil {
+		t.Fatal("delivery to a chat that blocked the bot succeeded")
+	}
+	if waiterErr != nil {
+		t.Fatalf("waiter err = %v, the converter's chat failure must not spread", waiterErr)
+	}
+	if got := telegram.sent(2); len(got) != 1 || got[0] != "https://cdn.example/track.mp3" {
+		t.Fatalf("waiter chat received %v, want its own conversion", got)
+	}
+	if links.forgotten != 0 {
+		t.Fatalf("link forgotten %d times after a chat failure", links.forgotten)
+	}
+}
+
+func TestSendTrackWaiterConvertsWithoutFileID(t *testing.T) {
+	telegram := &fakeTelegram{withoutFileID: true}
+	links := newFakeLinks()
+
+	converterErr, waiterErr := deliverConcurrently(t, telegram, links, 1, 2)
+	if converterErr != nil || waiterErr != nil {
+		t.Fatalf("errors: converter %v, waiter %v", converterErr, waiterErr)
+	}
+	if got := telegram.sent(2); len(got) != 1 {
+		t.Fatalf("waiter chat received %v, want one track", got)
+	}
+}
+
+func TestLinkRejected(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&gotgbot.TelegramError{Code: 400, Description: "Bad Request: failed to get HTTP URL content"}, true},
+		{&gotgbot.TelegramError{Code: 400, Description: "Bad Request: wrong file identifier/HTTP URL specified"}, true},
+		{&gotgbot.TelegramError{Code: 400, Description: "Bad Request: wrong type of the web page content"}, true},
+		{&gotgbot.TelegramError{Code: 400, Description: "Bad Request: chat not found"}, false},
+		{&gotgbot.TelegramError{Code: 403, Description: "Forbidden: bot was blocked by the user"}, false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := linkRejected(tt.err); got != tt.want {
+			t.Errorf("linkRejected(%v) = %t, want %t", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSentFormat(t *testing.T) {
+	tests := []struct {
+		message *gotgbot.Message
+		want    settings.Format
+	}{
+		{&gotgbot.Message{Audio: &gotgbot.Audio{FileId: "a"}}, settings.FormatAudio},
+		{&gotgbot.Message{Document: &gotgbot.Document{FileId: "d"}}, settings.FormatFile},
+		// Telegram presents a document it recognizes as music as audio, its file_id is an audio one.
+		{&gotgbot.Message{Audio: &gotgbot.Audio{FileId: "a"}, Document: &gotgbot.Document{FileId: "d"}}, settings.FormatAudio},
+	}
+	for _, tt := range tests {
+		if got := sentFormat(tt.message); got != tt.want {
+			t.Errorf("sentFormat(%+v) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
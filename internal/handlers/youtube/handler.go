@@ -2,21 +2,47 @@ package youtube
 
 import (
 	"context"
-	"time"
+	"sync"
+	"sync/atomic"
 
-	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/download"
+	"music-bot-v2/internal/handlers/commands"
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/inflight"
 	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/playlist"
 
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/callbackquery"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/choseninlineresult"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/inlinequery"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers/filters/message"
 )
 
 type musicSearcher interface {
-	SearchVideos(ctx context.Context, query string, page int, requester string) ([]music.VideoInfo, int, error)
+	SearchVideos(ctx context.Context, query string, page int, requester string, settings music.SearchSettings) (music.SearchPage, error)
+	PageWalk(ctx context.Context, query string, page int, requester string, settings music.SearchSettings) int
 	ResetSearchState(ctx context.Context, requester string)
 	MP3Link(ctx context.Context, id string) (string, error)
+	ForgetMP3Link(ctx context.Context, id string)
+	Video(ctx context.Context, id string) (music.VideoInfo, error)
+	Thumbnail(ctx context.Context, url string) ([]byte, error)
+	PlaylistTracks(ctx context.Context, playlistID string) ([]music.VideoInfo, error)
+}
+
+type playlistStore interface {
+	List(ctx context.Context, owner string) ([]playlist.Playlist, error)
+	Get(ctx context.Context, owner string, id int) (playlist.Playlist, error)
+	Create(ctx context.Context, owner string, name string) (playlist.Playlist, error)
+	Rename(ctx context.Context, owner string, id int, name string) error
+	Delete(ctx context.Context, owner string, id int) error
+	AddTrack(ctx context.Context, owner string, id int, track playlist.Track) (playlist.Playlist, error)
+	RemoveTrack(ctx context.Context, owner string, id int, trackID string) (playlist.Playlist, error)
+}
+
+type audioDownloader interface {
+	Fetch(ctx context.Context, url string) (*download.File, error)
 }
 
 type cacherService interface {
@@ -28,28 +54,139 @@ type cacherService interface {
 type Handler struct {
 	ctx        context.Context
 	music      musicSearcher
+	playlists  playlistStore
 	queryCache cacherService
 	panelCache cacherService
 	audioCache cacherService
+	// languageCache holds languages chosen with /language, the Telegram client's language applies otherwise.
+	languageCache cacherService
+	downloader    audioDownloader
+	limiter       rateLimiter
+	queue         deliveryQueue
+	settings      settingsStore
+	history       historyStore
+	favorites     favoritesStore
+
+	deliveries     inflight.Group[delivery]
+	conversionLock conversionLocker
+	commands       *commands.Registry
+	// bulkJobs numbers the queue jobs of bulk deliveries, which have no status message to be keyed by.
+	bulkJobs atomic.Uint64
+
+	// imports holds cancel functions of running playlist imports keyed by their panel message.
+	importsMu sync.Mutex
+	imports   map[string]context.CancelFunc
+}
+
+// Caches groups the caches the handler keeps per-user search state, languages and audio file_ids in.
+type Caches struct {
+	Query    cacherService
+	Panel    cacherService
+	Audio    cacherService
+	Language cacherService
 }
 
-func NewHandler(ctx context.Context, music musicSearcher) *Handler {
+type Option func(*Handler)
+
+// WithDownloader enables uploading audio from the bot when Telegram fails to fetch the converter link itself.
+func WithDownloader(downloader audioDownloader) Option {
+	return func(h *Handler) {
+		h.downloader = downloader
+	}
+}
+
+func NewHandler(ctx context.Context, music musicSearcher, playlists playlistStore, caches Caches, options ...Option) *Handler {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	return &Handler{
-		ctx:        ctx,
-		music:      music,
-		queryCache: cacher.NewRedis(cacher.QueryCacheDB, 0),
-		panelCache: cacher.NewRedis(cacher.PanelCacheDB, 48*time.Hour),
-		audioCache: cacher.NewRedis(cacher.AudioCacheDB, 0),
+	h := &Handler{
+		ctx:           ctx,
+		music:         music,
+		playlists:     playlists,
+		queryCache:    caches.Query,
+		panelCache:    caches.Panel,
+		audioCache:    caches.Audio,
+		languageCache: caches.Language,
+		imports:       make(map[string]context.CancelFunc),
 	}
+	for _, option := range options {
+		option(h)
+	}
+	h.commands = commands.NewRegistry(ctx, h.localizer,
+		commands.Command{
+			Name:        startCommand,
+			Description: i18n.CommandStart,
+			Scope:       commands.ScopePrivate,
+			Handler:     h.startCommand(),
+		},
+		commands.Command{
+			Name:        searchCommand,
+			Aliases:     []string{searchShortCommand},
+			Usage:       i18n.CommandSearchUsage,
+			Description: i18n.CommandSearch,
+			Handler:     h.searchCommand(),
+		},
+		commands.Command{
+			Name:        playlistCommand,
+			Description: i18n.CommandPlaylist,
+			Handler:     h.playlistCommand(),
+		},
+		commands.Command{
+			Name:        favoritesCommand,
+			Description: i18n.CommandFavorites,
+			Scope:       commands.ScopePrivate,
+			Handler:     h.favoritesCommand(),
+		},
+		commands.Command{
+			Name:        historyCommand,
+			Description: i18n.CommandHistory,
+			Scope:       commands.ScopePrivate,
+			Handler:     h.historyCommand(),
+		},
+		commands.Command{
+			Name:        settingsCommand,
+			Description: i18n.CommandSettings,
+			Scope:       commands.ScopePrivate,
+			Handler:     h.settingsCommand(),
+		},
+		commands.Command{
+			Name:        languageCommand,
+			Description: i18n.CommandLanguage,
+			Handler:     h.languageCommand(),
+		},
+	)
+	return h
+}
+
+// Commands is the registry of the bot's commands, for publishing the command menus.
+func (h *Handler) Commands() *commands.Registry {
+	return h.commands
 }
 
 func (h *Handler) Handlers() []ext.Handler {
-	return []ext.Handler{
+	// Commands go first, the text handler would search them otherwise.
+	return append(h.commands.Handlers(),
 		handlers.NewMessage(message.Text, h.searchText()),
 		handlers.NewCallback(callbackquery.Prefix(paginationCallbackPrefix), h.paginationCallback()),
 		handlers.NewCallback(callbackquery.Prefix(searchCallbackPrefix), h.getAudioCallback()),
-	}
+		handlers.NewCallback(callbackquery.Prefix(deliveryCancelCallbackPrefix), h.deliveryCancelCallback()),
+		handlers.NewCallback(callbackquery.Prefix(playlistAddCallbackPrefix), h.playlistAddCallback()),
+		handlers.NewCallback(callbackquery.Prefix(playlistPutCallbackPrefix), h.playlistPutCallback()),
+		handlers.NewCallback(callbackquery.Prefix(playlistOpenCallbackPrefix), h.playlistOpenCallback()),
+		handlers.NewCallback(callbackquery.Prefix(playlistRemoveCallbackPrefix), h.playlistRemoveCallback()),
+		handlers.NewCallback(callbackquery.Prefix(playlistPlayCallbackPrefix), h.playlistPlayCallback()),
+		handlers.NewCallback(callbackquery.Prefix(importStartCallbackPrefix), h.importStartCallback()),
+		handlers.NewCallback(callbackquery.Prefix(importStopCallbackPrefix), h.importStopCallback()),
+		handlers.NewCallback(callbackquery.Prefix(importDismissCallbackPrefix), h.importDismissCallback()),
+		handlers.NewCallback(callbackquery.Prefix(languageCallbackPrefix), h.languageCallback()),
+		handlers.NewCallback(callbackquery.Prefix(settingsCallbackPrefix), h.settingsCallback()),
+		handlers.NewCallback(callbackquery.Prefix(historyRunCallbackPrefix), h.historyRunCallback()),
+		handlers.NewCallback(callbackquery.Prefix(historyClearCallbackPrefix), h.historyClearCallback()),
+		handlers.NewCallback(callbackquery.Prefix(favoriteSaveCallbackPrefix), h.favoriteSaveCallback()),
+		handlers.NewCallback(callbackquery.Prefix(favoriteOpenCallbackPrefix), h.favoriteOpenCallback()),
+		handlers.NewCallback(callbackquery.Prefix(favoriteSendCallbackPrefix), h.favoriteSendCallback()),
+		handlers.NewCallback(callbackquery.Prefix(favoriteRemoveCallbackPrefix), h.favoriteRemoveCallback()),
+		handlers.NewInlineQuery(inlinequery.All, h.inlineQuery()),
+		handlers.NewChosenInlineResult(choseninlineresult.All, h.chosenInlineResult()),
+	)
 }
@@ -0,0 +1,174 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/history"
+	"music-bot-v2/internal/i18n"
+)
+
+const (
+	historyCommand             = "history"
+	historyRunCallbackPrefix   = "hsr:"
+	historyClearCallbackPrefix = "hsc:"
+)
+
+type historyStore interface {
+	List(ctx context.Context, user string) ([]history.Entry, error)
+	Get(ctx context.Context, user string, id int) (history.Entry, error)
+	Add(ctx context.Context, user string, query string, results int) error
+	Clear(ctx context.Context, user string) error
+}
+
+// WithHistory records the searches of each user for /history.
+func WithHistory(store historyStore) Option {
+	return func(h *Handler) {
+		h.history = store
+	}
+}
+
+// recordSearch adds the query to the user's history.
+func (h *Handler) recordSearch(user string, query string, results int) {
+	if h.history == nil || user == "" {
+		return
+	}
+	if err := h.history.Add(h.ctx, user, query, results); err != nil {
+		log.Printf("history add user=%s err=%v", user, err)
+	}
+}
+
+func (h *Handler) historyCommand() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing message context")
+		}
+
+		tr := h.localizer(ctx)
+		chatID := ctx.EffectiveChat.Id
+		if h.history == nil {
+			_, err := b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.HistoryEmpty), nil)
+			return err
+		}
+
+		entries, err := h.history.List(h.ctx, requesterID(ctx))
+		if err != nil {
+			_, _ = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.HistoryFailed), nil)
+			return err
+		}
+		if len(entries) == 0 {
+			_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.HistoryEmpty), nil)
+			return err
+		}
+		_, err = b.SendMessageWithContext(h.ctx, chatID, historyText(tr, entries, time.Now()), &gotgbot.SendMessageOpts{
+			ReplyMarkup: historyKeyboard(tr, entries),
+		})
+		return err
+	}
+}
+
+// historyRunCallback runs a search from the history again, which also moves it to the top.
+func (h *Handler) historyRunCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.music == nil {
+			return errors.New("music consumer is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		id, err := strconv.Atoi(strings.TrimPrefix(ctx.CallbackQuery.Data, historyRunCallbackPrefix))
+		if err != nil || h.history == nil {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.HistoryNotFound))
+		}
+
+		entry, err := h.history.Get(h.ctx, requesterID(ctx), id)
+		if errors.Is(err, history.ErrNotFound) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.HistoryNotFound))
+		}
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.HistoryFailed))
+			return err
+		}
+
+		_ = answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+		return h.search(b, ctx, entry.Query)
+	}
+}
+
+func (h *Handler) historyClearCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveMessage == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		if h.history != nil {
+			if err := h.history.Clear(h.ctx, requesterID(ctx)); err != nil {
+				_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.HistoryFailed))
+				return err
+			}
+		}
+
+		_, _, _ = b.EditMessageTextWithContext(h.ctx, tr.T(i18n.HistoryCleared), &gotgbot.EditMessageTextOpts{
+			ChatId:      ctx.EffectiveMessage.Chat.Id,
+			MessageId:   ctx.EffectiveMessage.MessageId,
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{}},
+		})
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	}
+}
+
+// historyText lists the searches with their result counts and how long ago they were run.
+func historyText(tr i18n.Localizer, entries []history.Entry, now time.Time) string {
+	lines := make([]string, 0, len(entries)+1)
+	lines = append(lines, tr.T(i18n.HistoryTitle))
+	for i, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%d. %s — %s, %s",
+			i+1, entry.Query, tr.N(i18n.HistoryResults, entry.Results), timeAgo(tr, now.Sub(entry.SearchedAt))))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func historyKeyboard(tr i18n.Localizer, entries []history.Entry) gotgbot.InlineKeyboardMarkup {
+	rows := make([][]gotgbot.InlineKeyboardButton, 0, len(entries)+1)
+	for i, entry := range entries {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{{
+			Text:         trimButtonLabel(fmt.Sprintf("%d. %s", i+1, entry.Query)),
+			CallbackData: historyRunCallbackPrefix + strconv.Itoa(entry.ID),
+		}})
+	}
+	rows = append(rows, []gotgbot.InlineKeyboardButton{{
+		Text:         tr.T(i18n.HistoryClear),
+		CallbackData: historyClearCallbackPrefix,
+	}})
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func timeAgo(tr i18n.Localizer, d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return tr.T(i18n.HistoryJustNow)
+	case d < time.Hour:
+		return tr.N(i18n.HistoryMinutesAgo, int(d/time.Minute))
+	case d < 24*time.Hour:
+		return tr.N(i18n.HistoryHoursAgo, int(d/time.Hour))
+	}
+	return tr.N(i18n.HistoryDaysAgo, int(d/(24*time.Hour)))
+}
@@ -0,0 +1,195 @@
+package youtube
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/ratelimit"
+	youtubeapi "music-bot-v2/internal/youtube"
+)
+
+const (
+	inlineRequesterPrefix = "inline#"
+	inlineCacheTimeSec    = 300
+)
+
+func (h *Handler) inlineQuery() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.music == nil {
+			return errors.New("music consumer is nil")
+		}
+		if ctx == nil || ctx.InlineQuery == nil {
+			return errors.New("missing inline query")
+		}
+
+		tr := h.localizer(ctx)
+		iq := ctx.InlineQuery
+		query := strings.TrimSpace(iq.Query)
+		if query == "" {
+			_, err := b.AnswerInlineQueryWithContext(h.ctx, iq.Id, []gotgbot.InlineQueryResult{}, &gotgbot.AnswerInlineQueryOpts{
+				IsPersonal: true,
+			})
+			return err
+		}
+
+		page, err := parseInlineOffset(iq.Offset)
+		if err != nil {
+			return err
+		}
+
+		kind := ratelimit.Search
+		if page > 0 {
+			kind = ratelimit.Page
+		}
+		if text, ok := h.allow(tr, kind, strconv.FormatInt(iq.From.Id, 10)); !ok {
+			// Inline answers cannot carry a message, the button above the results shows the hint instead.
+			_, err := b.AnswerInlineQueryWithContext(h.ctx, iq.Id, []gotgbot.InlineQueryResult{}, &gotgbot.AnswerInlineQueryOpts{
+				IsPersonal: true,
+				Button: &gotgbot.InlineQueryResultsButton{
+					Text:           text,
+					StartParameter: "slow_down",
+				},
+			})
+			return err
+		}
+
+		userPrefix := inlineUserPrefix(iq.From.Id)
+		if page == 0 {
+			// A fresh query from the same user supersedes the previous one, drop its cached pages and tokens.
+			h.music.ResetSearchState(h.ctx, userPrefix)
+		}
+
+		result, err := h.music.SearchVideos(h.ctx, query, page, userPrefix+query, music.SearchSettings{})
+		if err != nil {
+			_, _ = b.AnswerInlineQueryWithContext(h.ctx, iq.Id, []gotgbot.InlineQueryResult{}, &gotgbot.AnswerInlineQueryOpts{
+				IsPersonal: true,
+			})
+			if errors.Is(err, music.ErrPageNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		nextOffset := ""
+		if page+1 < result.Pages && len(result.Items) > 0 {
+			nextOffset = strconv.Itoa(page + 1)
+		}
+
+		_, err = b.AnswerInlineQueryWithContext(h.ctx, iq.Id, h.buildInlineResults(tr, result.Items, query), &gotgbot.AnswerInlineQueryOpts{
+			CacheTime:  inlineCacheTimeSec,
+			IsPersonal: true,
+			NextOffset: nextOffset,
+		})
+		return err
+	}
+}
+
+// chosenInlineResult replaces the placeholder photo sent for a track without a cached file_id with the audio itself.
+// Telegram only reports chosen results when inline feedback is enabled for the bot in @BotFather.
+func (h *Handler) chosenInlineResult() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.music == nil {
+			return errors.New("music consumer is nil")
+		}
+		if ctx == nil || ctx.ChosenInlineResult == nil {
+			return errors.New("missing chosen inline result")
+		}
+
+		result := ctx.ChosenInlineResult
+		if result.InlineMessageId == "" {
+			return nil
+		}
+
+		trackID := strings.TrimSpace(result.ResultId)
+		if trackID == "" {
+			return errors.New("invalid track id")
+		}
+		tr := h.localizer(ctx)
+		keyboard := inlineResultKeyboard(tr, result.Query)
+
+		media := gotgbot.InputMediaAudio{}
+		if fileID := h.getAudioFileID(trackID); fileID != "" {
+			media.Media = gotgbot.InputFileByID(fileID)
+		} else {
+			link, err := h.music.MP3Link(h.ctx, trackID)
+			if err != nil {
+				_, _, _ = b.EditMessageCaptionWithContext(h.ctx, &gotgbot.EditMessageCaptionOpts{
+					InlineMessageId: result.InlineMessageId,
+					Caption:         tr.T(i18n.TrackFailed),
+					ReplyMarkup:     *keyboard,
+				})
+				return err
+			}
+			media.Media = gotgbot.InputFileByURL(link)
+			if meta, ok := h.trackMetadata(h.ctx, trackID); ok {
+				media.Title = meta.title
+				media.Performer = meta.performer
+				media.Duration = meta.duration
+			}
+		}
+
+		_, _, err := b.EditMessageMediaWithContext(h.ctx, media, &gotgbot.EditMessageMediaOpts{
+			InlineMessageId: result.InlineMessageId,
+			ReplyMarkup:     *keyboard,
+		})
+		return err
+	}
+}
+
+func (h *Handler) buildInlineResults(tr i18n.Localizer, items []music.VideoInfo, query string) []gotgbot.InlineQueryResult {
+	keyboard := inlineResultKeyboard(tr, query)
+	results := make([]gotgbot.InlineQueryResult, 0, len(items))
+	for _, item := range items {
+		if fileID := h.getAudioFileID(item.ID); fileID != "" {
+			results = append(results, gotgbot.InlineQueryResultCachedAudio{
+				Id:          item.ID,
+				AudioFileId: fileID,
+				ReplyMarkup: keyboard,
+			})
+			continue
+		}
+		// The placeholder is the video's thumbnail: editMessageMedia turns only media messages into audio, not text.
+		// The reply markup is required: without it Telegram does not report inline_message_id for the result.
+		thumbnail := youtubeapi.ThumbnailURL(item.ID)
+		results = append(results, gotgbot.InlineQueryResultPhoto{
+			Id:           item.ID,
+			PhotoUrl:     thumbnail,
+			ThumbnailUrl: thumbnail,
+			Title:        item.Label(),
+			Description:  item.ChannelTitle,
+			Caption:      tr.T(i18n.InlineLoading, item.Title),
+			ReplyMarkup:  keyboard,
+		})
+	}
+	return results
+}
+
+func inlineResultKeyboard(tr i18n.Localizer, query string) *gotgbot.InlineKeyboardMarkup {
+	return &gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+		Text:                         tr.T(i18n.InlineSearchMore),
+		SwitchInlineQueryCurrentChat: &query,
+	}}}}
+}
+
+func parseInlineOffset(offset string) (int, error) {
+	if offset == "" {
+		return 0, nil
+	}
+	page, err := strconv.Atoi(offset)
+	if err != nil || page < 0 {
+		return 0, errors.New("invalid inline offset")
+	}
+	return page, nil
+}
+
+// inlineUserPrefix namespaces inline search state per user so it never collides with chat searches.
+func inlineUserPrefix(userID int64) string {
+	return inlineRequesterPrefix + strconv.FormatInt(userID, 10) + "#"
+}
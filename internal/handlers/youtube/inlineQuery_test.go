@@ -0,0 +1,43 @@
+package youtube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+
+	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/music"
+)
+
+func TestBuildInlineResults(t *testing.T) {
+	h := &Handler{ctx: context.Background(), audioCache: cacher.NewMemory(cacher.AudioCacheDB, 0, 0)}
+	h.setAudioFileID("cachedTrack", "file-id")
+
+	items := []music.VideoInfo{
+		{ID: "cachedTrack", Title: "Cached"},
+		{ID: "freshTrack1", Title: "Fresh", ChannelTitle: "Channel"},
+	}
+	results := h.buildInlineResults(i18n.New(i18n.EN), items, "query")
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	cached, ok := results[0].(gotgbot.InlineQueryResultCachedAudio)
+	if !ok || cached.AudioFileId != "file-id" || cached.Id != "cachedTrack" {
+		t.Fatalf("cached track result = %#v", results[0])
+	}
+
+	// The placeholder must be a media message, editMessageMedia cannot turn text into audio.
+	placeholder, ok := results[1].(gotgbot.InlineQueryResultPhoto)
+	if !ok {
+		t.Fatalf("uncached track result is %T, want a photo placeholder", results[1])
+	}
+	if placeholder.Id != "freshTrack1" || placeholder.PhotoUrl != "https://i.ytimg.com/vi/freshTrack1/hqdefault.jpg" {
+		t.Fatalf("placeholder = %#v", placeholder)
+	}
+	if placeholder.ReplyMarkup == nil || placeholder.Caption == "" {
+		t.Fatalf("placeholder without keyboard or caption: %#v", placeholder)
+	}
+}
@@ -0,0 +1,127 @@
+package youtube
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+)
+
+const (
+	languageCommand        = "language"
+	languageCallbackPrefix = "lng:"
+)
+
+// localizer picks the user's language: the one chosen with /language, else the one of their Telegram client.
+func (h *Handler) localizer(ctx *ext.Context) i18n.Localizer {
+	if ctx == nil || ctx.EffectiveUser == nil {
+		return i18n.New(i18n.Default)
+	}
+	if lang, ok := h.getLanguage(strconv.FormatInt(ctx.EffectiveUser.Id, 10)); ok {
+		return i18n.New(lang)
+	}
+	return i18n.New(i18n.Match(ctx.EffectiveUser.LanguageCode))
+}
+
+func (h *Handler) languageCommand() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing message context")
+		}
+
+		tr := h.localizer(ctx)
+		rows := make([][]gotgbot.InlineKeyboardButton, 0, len(i18n.Languages())+1)
+		for _, lang := range i18n.Languages() {
+			rows = append(rows, []gotgbot.InlineKeyboardButton{{
+				Text:         lang.Name(),
+				CallbackData: languageCallbackPrefix + string(lang),
+			}})
+		}
+		rows = append(rows, []gotgbot.InlineKeyboardButton{{
+			Text:         tr.T(i18n.LanguageAuto),
+			CallbackData: languageCallbackPrefix,
+		}})
+		_, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, tr.T(i18n.LanguageChoose), &gotgbot.SendMessageOpts{
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows},
+		})
+		return err
+	}
+}
+
+// languageCallback stores the chosen language; an empty choice returns to the Telegram client's language.
+func (h *Handler) languageCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveUser == nil {
+			return errors.New("missing callback query context")
+		}
+
+		user := strconv.FormatInt(ctx.EffectiveUser.Id, 10)
+		code := strings.TrimPrefix(ctx.CallbackQuery.Data, languageCallbackPrefix)
+		if code == "" {
+			h.clearLanguage(user)
+		} else {
+			lang, ok := i18n.Parse(code)
+			if !ok {
+				return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+			}
+			h.setLanguage(user, lang)
+		}
+
+		tr := h.localizer(ctx)
+		text := tr.T(i18n.LanguageSet, tr.Lang().Name())
+		if ctx.EffectiveMessage != nil {
+			_, _, _ = b.EditMessageTextWithContext(h.ctx, text, &gotgbot.EditMessageTextOpts{
+				ChatId:      ctx.EffectiveMessage.Chat.Id,
+				MessageId:   ctx.EffectiveMessage.MessageId,
+				ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{}},
+			})
+		}
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	}
+}
+
+// Cache for the language chosen with /language by user.
+func (h *Handler) setLanguage(user string, lang i18n.Lang) {
+	if h.languageCache == nil || user == "" {
+		return
+	}
+	if err := h.languageCache.Set(h.ctx, user, string(lang)); err != nil {
+		log.Printf("cache set language user=%s err=%v", user, err)
+	}
+}
+
+func (h *Handler) getLanguage(user string) (i18n.Lang, bool) {
+	if h.languageCache == nil || user == "" {
+		return "", false
+	}
+	value, ok, err := h.languageCache.Get(h.ctx, user)
+	if err != nil {
+		log.Printf("cache get language user=%s err=%v", user, err)
+		return "", false
+	}
+	if !ok || value == "" {
+		return "", false
+	}
+	return i18n.Parse(value)
+}
+
+func (h *Handler) clearLanguage(user string) {
+	if h.languageCache == nil || user == "" {
+		return
+	}
+	if err := h.languageCache.Set(h.ctx, user, ""); err != nil {
+		log.Printf("cache clear language user=%s err=%v", user, err)
+	}
+}
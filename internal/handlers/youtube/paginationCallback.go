@@ -9,6 +9,10 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/ratelimit"
 )
 
 func (h *Handler) paginationCallback() handlers.Response {
@@ -20,39 +24,58 @@ func (h *Handler) paginationCallback() handlers.Response {
 			return errors.New("missing callback query")
 		}
 
-		page, err := parsePaginationPage(ctx.CallbackQuery.Data)
+		if ctx.CallbackQuery.Data == paginationCurrentData {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+		}
+
+		tr := h.localizer(ctx)
+		page, owner, err := parsePaginationPage(ctx.CallbackQuery.Data)
 		if err != nil {
-			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, "Invalid page.")
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPage))
 			return err
 		}
 
 		requester := requesterID(ctx)
-		query := strings.TrimSpace(h.getQuery(requester))
+		if owner != "" && owner != requester {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SearchNotOwner))
+		}
+		if ctx.EffectiveMessage == nil {
+			return errors.New("missing message to edit")
+		}
+		key := searchKey(ctx.EffectiveMessage.Chat.Id, requester)
+		query := strings.TrimSpace(h.getQuery(key))
 		if query == "" {
-			return answerCallback(h.ctx, b, ctx.CallbackQuery, "Search expired. Send a new query.")
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SearchExpired))
 		}
 
-		items, total, err := h.music.SearchVideos(h.ctx, query, page, requester)
-		if err != nil {
-			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, "Search failed. Please try again.")
-			return err
+		// A jump to a page without a known token walks the pages before it, each walked page is a search too.
+		prefs := h.userSettings(ctx)
+		walk := h.music.PageWalk(h.ctx, query, page, key, searchSettings(prefs))
+		if walk > music.MaxPageWalk {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SearchTooFar))
 		}
-
-		if len(items) == 0 {
-			return answerCallback(h.ctx, b, ctx.CallbackQuery, "No videos found.")
+		if text, ok := h.allowN(tr, ratelimit.Page, requester, walk+1); !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, text)
 		}
 
-		totalPages := pageCount(total, searchPageLimit)
-		if totalPages > 0 && page >= totalPages {
-			return answerCallback(h.ctx, b, ctx.CallbackQuery, "No more pages.")
+		result, err := h.music.SearchVideos(h.ctx, query, page, key, searchSettings(prefs))
+		if errors.Is(err, music.ErrPageNotFound) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SearchNoMore))
+		}
+		if errors.Is(err, music.ErrPageTooFar) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SearchTooFar))
+		}
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SearchPageFailed))
+			return err
 		}
 
-		if ctx.EffectiveMessage == nil {
-			return errors.New("missing message to edit")
+		if len(result.Items) == 0 {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SearchNoResults))
 		}
 
-		keyboard := buildSearchKeyboard(items, page, total)
-		_, _, err = b.EditMessageTextWithContext(h.ctx, searchMessageText(page, total), &gotgbot.EditMessageTextOpts{
+		keyboard := buildSearchKeyboard(result, page, owner)
+		_, _, err = b.EditMessageTextWithContext(h.ctx, searchMessageText(tr, page, result.Pages), &gotgbot.EditMessageTextOpts{
 			ChatId:      ctx.EffectiveMessage.Chat.Id,
 			MessageId:   ctx.EffectiveMessage.MessageId,
 			ReplyMarkup: keyboard,
@@ -65,18 +88,19 @@ func (h *Handler) paginationCallback() handlers.Response {
 	}
 }
 
-func parsePaginationPage(data string) (int, error) {
+// parsePaginationPage reads the page and, for group panels, the user the panel belongs to.
+func parsePaginationPage(data string) (int, string, error) {
 	if !strings.HasPrefix(data, paginationCallbackPrefix) {
-		return 0, errors.New("unexpected callback data")
+		return 0, "", errors.New("unexpected callback data")
 	}
 
-	pageStr := strings.TrimPrefix(data, paginationCallbackPrefix)
+	pageStr, owner, _ := strings.Cut(strings.TrimPrefix(data, paginationCallbackPrefix), ":")
 	page, err := strconv.Atoi(pageStr)
 	if err != nil || page < 0 {
-		return 0, errors.New("invalid page")
+		return 0, "", errors.New("invalid page")
 	}
 
-	return page, nil
+	return page, owner, nil
 }
 
 func answerCallback(ctx context.Context, b *gotgbot.Bot, cq *gotgbot.CallbackQuery, text string) error {
@@ -0,0 +1,30 @@
+package youtube
+
+import "testing"
+
+func TestParsePaginationPage(t *testing.T) {
+	tests := []struct {
+		data      string
+		wantPage  int
+		wantOwner string
+		wantErr   bool
+	}{
+		{data: "ytp:3", wantPage: 3},
+		{data: "ytp:0:12345", wantPage: 0, wantOwner: "12345"},
+		{data: paginationData(7, "42"), wantPage: 7, wantOwner: "42"},
+		{data: "ytp:-1", wantErr: true},
+		{data: "ytp:x", wantErr: true},
+		{data: "ytp:", wantErr: true},
+		{data: "yt:3", wantErr: true},
+	}
+	for _, tt := range tests {
+		page, owner, err := parsePaginationPage(tt.data)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parsePaginationPage(%q) err = %v, want error %t", tt.data, err, tt.wantErr)
+			continue
+		}
+		if page != tt.wantPage || owner != tt.wantOwner {
+			t.Errorf("parsePaginationPage(%q) = %d, %q, want %d, %q", tt.data, page, owner, tt.wantPage, tt.wantOwner)
+		}
+	}
+}
@@ -1,30 +1,33 @@
 package youtube
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
+
+	"music-bot-v2/internal/music"
 )
 
-// Cache for the search panel message (chatID/messageID) by requester.
-func (h *Handler) setPanelMessage(requester string, chatID int64, messageID int64) {
-	if requester == "" {
+// Cache for the search panel message (chatID/messageID) by search key.
+func (h *Handler) setPanelMessage(key string, chatID int64, messageID int64) {
+	if key == "" {
 		return
 	}
 	value := fmt.Sprintf("%d:%d", chatID, messageID)
-	if err := h.panelCache.Set(h.ctx, requester, value); err != nil {
-		log.Printf("cache set panel requester=%s err=%v", requester, err)
+	if err := h.panelCache.Set(h.ctx, key, value); err != nil {
+		log.Printf("cache set panel key=%s err=%v", key, err)
 	}
 }
 
-func (h *Handler) getPanelMessage(requester string) (int64, int64, bool) {
-	if requester == "" {
+func (h *Handler) getPanelMessage(key string) (int64, int64, bool) {
+	if key == "" {
 		return 0, 0, false
 	}
-	value, ok, err := h.panelCache.Get(h.ctx, requester)
+	value, ok, err := h.panelCache.Get(h.ctx, key)
 	if err != nil {
-		log.Printf("cache get panel requester=%s err=%v", requester, err)
+		log.Printf("cache get panel key=%s err=%v", key, err)
 		return 0, 0, false
 	}
 	if !ok || value == "" {
@@ -45,11 +48,52 @@ func (h *Handler) getPanelMessage(requester string) (int64, int64, bool) {
 	return chatID, messageID, true
 }
 
-func (h *Handler) clearPanelMessage(requester string) {
-	if requester == "" {
+func (h *Handler) clearPanelMessage(key string) {
+	if key == "" {
+		return
+	}
+	if err := h.panelCache.Set(h.ctx, key, ""); err != nil {
+		log.Printf("cache clear panel key=%s err=%v", key, err)
+	}
+}
+
+// importPanel is what a playlist import panel was drawn from: the link sender, the only user who may start, stop
+// or dismiss the import, and the tracks it lists, which are delivered as shown instead of being fetched again.
+type importPanel struct {
+	Owner      string            `json:"owner"`
+	PlaylistID string            `json:"playlist_id"`
+	Tracks     []music.VideoInfo `json:"tracks"`
+}
+
+// Cache for playlist import panels by panel message.
+func (h *Handler) setImportPanel(chatID int64, messageID int64, panel importPanel) {
+	key := importPanelKey(chatID, messageID)
+	value, err := json.Marshal(panel)
+	if err != nil {
 		return
 	}
-	if err := h.panelCache.Set(h.ctx, requester, ""); err != nil {
-		log.Printf("cache clear panel requester=%s err=%v", requester, err)
+	if err := h.panelCache.Set(h.ctx, key, string(value)); err != nil {
+		log.Printf("cache set import panel key=%s err=%v", key, err)
 	}
 }
+
+func (h *Handler) getImportPanel(chatID int64, messageID int64) (importPanel, bool) {
+	key := importPanelKey(chatID, messageID)
+	value, ok, err := h.panelCache.Get(h.ctx, key)
+	if err != nil {
+		log.Printf("cache get import panel key=%s err=%v", key, err)
+		return importPanel{}, false
+	}
+	if !ok || value == "" {
+		return importPanel{}, false
+	}
+	var panel importPanel
+	if err := json.Unmarshal([]byte(value), &panel); err != nil || panel.Owner == "" {
+		return importPanel{}, false
+	}
+	return panel, true
+}
+
+func importPanelKey(chatID int64, messageID int64) string {
+	return "import#" + messageKey(chatID, messageID)
+}
@@ -0,0 +1,398 @@
+package youtube
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/playlist"
+	"music-bot-v2/internal/ratelimit"
+	"music-bot-v2/internal/settings"
+)
+
+const (
+	playlistAddCallbackPrefix    = "pla:"
+	playlistPutCallbackPrefix    = "plt:"
+	playlistOpenCallbackPrefix   = "plo:"
+	playlistRemoveCallbackPrefix = "plr:"
+	playlistPlayCallbackPrefix   = "plx:"
+)
+
+// playlistAddCallback handles ➕ on a search result: adds right away when there is one playlist, asks otherwise.
+// Anyone may add a result they see, the track goes to the playlists of whoever tapped.
+func (h *Handler) playlistAddCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.playlists == nil {
+			return errors.New("playlist store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, playlistAddCallbackPrefix, 1)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidTrack))
+			return err
+		}
+		trackID := args[0]
+
+		owner := requesterID(ctx)
+		list, err := h.playlists.List(h.ctx, owner)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistFailed))
+			return err
+		}
+
+		switch len(list) {
+		case 0:
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistCreateFirst))
+		case 1:
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, h.addTrackToPlaylist(tr, owner, list[0].ID, trackID))
+		}
+
+		rows := make([][]gotgbot.InlineKeyboardButton, 0, len(list))
+		for _, p := range list {
+			rows = append(rows, []gotgbot.InlineKeyboardButton{{
+				Text:         trimButtonLabel(fmt.Sprintf("📁 %s (%d)", p.Name, len(p.Tracks))),
+				CallbackData: fmt.Sprintf("%s%s:%d:%s", playlistPutCallbackPrefix, owner, p.ID, trackID),
+			}})
+		}
+		_, err = b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, tr.T(i18n.PlaylistChoose), &gotgbot.SendMessageOpts{
+			ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows},
+		})
+		if err != nil {
+			return err
+		}
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	}
+}
+
+// playlistPutCallback handles the choice made in the "Add to which playlist?" message.
+func (h *Handler) playlistPutCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.playlists == nil {
+			return errors.New("playlist store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil {
+			return errors.New("missing callback query")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, playlistPutCallbackPrefix, 3)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPlaylist))
+			return err
+		}
+		owner := args[0]
+		if owner != requesterID(ctx) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistNotOwner))
+		}
+		playlistID, err := strconv.Atoi(args[1])
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPlaylist))
+			return err
+		}
+
+		text := h.addTrackToPlaylist(tr, owner, playlistID, args[2])
+		if ctx.EffectiveMessage != nil {
+			_, _ = b.DeleteMessageWithContext(h.ctx, ctx.EffectiveMessage.Chat.Id, ctx.EffectiveMessage.MessageId, nil)
+		}
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+	}
+}
+
+// playlistOpenCallback renders a playlist page in place; playlist ID 0 renders the list of playlists.
+func (h *Handler) playlistOpenCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.playlists == nil {
+			return errors.New("playlist store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil {
+			return errors.New("missing callback query")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, playlistOpenCallbackPrefix, 3)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPlaylist))
+			return err
+		}
+		owner := args[0]
+		if owner != requesterID(ctx) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistNotOwner))
+		}
+		playlistID, err := strconv.Atoi(args[1])
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPlaylist))
+			return err
+		}
+		page, err := strconv.Atoi(args[2])
+		if err != nil || page < 0 {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPage))
+			return errors.New("invalid page")
+		}
+
+		if playlistID == 0 {
+			list, err := h.playlists.List(h.ctx, owner)
+			if err != nil {
+				_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistFailed))
+				return err
+			}
+			if len(list) == 0 {
+				return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistsNone))
+			}
+			return h.editPlaylistMessage(b, ctx, tr.T(i18n.PlaylistsTitle), buildPlaylistsKeyboard(list, owner))
+		}
+
+		p, err := h.playlists.Get(h.ctx, owner, playlistID)
+		if err != nil {
+			text, known := playlistErrorText(tr, err)
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+			if known {
+				return nil
+			}
+			return err
+		}
+
+		text, keyboard := buildPlaylistView(tr, p, page, owner)
+		return h.editPlaylistMessage(b, ctx, text, keyboard)
+	}
+}
+
+func (h *Handler) playlistRemoveCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.playlists == nil {
+			return errors.New("playlist store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil {
+			return errors.New("missing callback query")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, playlistRemoveCallbackPrefix, 4)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidTrack))
+			return err
+		}
+		owner := args[0]
+		if owner != requesterID(ctx) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistNotOwner))
+		}
+		playlistID, err := strconv.Atoi(args[1])
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPlaylist))
+			return err
+		}
+		page, _ := strconv.Atoi(args[3])
+
+		p, err := h.playlists.RemoveTrack(h.ctx, owner, playlistID, args[2])
+		if err != nil {
+			text, known := playlistErrorText(tr, err)
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+			if known {
+				return nil
+			}
+			return err
+		}
+
+		text, keyboard := buildPlaylistView(tr, p, page, owner)
+		return h.editPlaylistMessage(b, ctx, text, keyboard)
+	}
+}
+
+func (h *Handler) playlistPlayCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.playlists == nil {
+			return errors.New("playlist store is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		args, err := parseCallbackArgs(ctx.CallbackQuery.Data, playlistPlayCallbackPrefix, 2)
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPlaylist))
+			return err
+		}
+		owner := args[0]
+		if owner != requesterID(ctx) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistNotOwner))
+		}
+		playlistID, err := strconv.Atoi(args[1])
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.InvalidPlaylist))
+			return err
+		}
+
+		p, err := h.playlists.Get(h.ctx, owner, playlistID)
+		if err != nil {
+			text, known := playlistErrorText(tr, err)
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+			if known {
+				return nil
+			}
+			return err
+		}
+		if len(p.Tracks) == 0 {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.PlaylistEmpty))
+		}
+		if text, ok := h.allow(tr, ratelimit.Download, owner); !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+		}
+
+		// Answer first: delivering a whole playlist takes longer than Telegram waits for a callback answer.
+		_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.N(i18n.PlaylistSending, len(p.Tracks)))
+		go h.playAll(tr, h.userSettings(ctx), b, ctx.EffectiveChat.Id, owner, p)
+		return nil
+	}
+}
+
+// playAll sends every track of the playlist in order, skipping tracks that fail to load.
+func (h *Handler) playAll(tr i18n.Localizer, prefs settings.Settings, b *gotgbot.Bot, chatID int64, owner string, p playlist.Playlist) {
+	trackIDs := make([]string, 0, len(p.Tracks))
+	for _, track := range p.Tracks {
+		trackIDs = append(trackIDs, track.ID)
+	}
+	_, failed := h.deliverTracks(h.ctx, tr, prefs, b, chatID, owner, trackIDs, nil)
+	if h.ctx.Err() != nil {
+		return
+	}
+	if failed > 0 {
+		_, _ = b.SendMessageWithContext(h.ctx, chatID, tr.N(i18n.PlaylistSendFailed, len(p.Tracks), failed, len(p.Tracks)), nil)
+	}
+}
+
+func (h *Handler) addTrackToPlaylist(tr i18n.Localizer, owner string, playlistID int, trackID string) string {
+	track := playlist.Track{ID: trackID, Title: trackID}
+	if info, err := h.music.Video(h.ctx, trackID); err != nil {
+		log.Printf("playlist add video track_id=%s err=%v", trackID, err)
+	} else {
+		track.Title = info.Label()
+	}
+
+	p, err := h.playlists.AddTrack(h.ctx, owner, playlistID, track)
+	if err != nil {
+		text, known := playlistErrorText(tr, err)
+		if !known {
+			log.Printf("playlist add track_id=%s playlist_id=%d err=%v", trackID, playlistID, err)
+		}
+		return text
+	}
+	return tr.T(i18n.PlaylistAdded, p.Name)
+}
+
+func (h *Handler) editPlaylistMessage(b *gotgbot.Bot, ctx *ext.Context, text string, keyboard gotgbot.InlineKeyboardMarkup) error {
+	if ctx.EffectiveMessage == nil {
+		return errors.New("missing message to edit")
+	}
+	_, _, err := b.EditMessageTextWithContext(h.ctx, text, &gotgbot.EditMessageTextOpts{
+		ChatId:      ctx.EffectiveMessage.Chat.Id,
+		MessageId:   ctx.EffectiveMessage.MessageId,
+		ReplyMarkup: keyboard,
+	})
+	if err != nil {
+		return err
+	}
+	return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+}
+
+// buildPlaylistsKeyboard lists the owner's playlists; the owner is encoded in the buttons, which work only for them.
+func buildPlaylistsKeyboard(list []playlist.Playlist, owner string) gotgbot.InlineKeyboardMarkup {
+	rows := make([][]gotgbot.InlineKeyboardButton, 0, len(list))
+	for i, p := range list {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{{
+			Text:         trimButtonLabel(fmt.Sprintf("%d. 📁 %s (%d)", i+1, p.Name, len(p.Tracks))),
+			CallbackData: fmt.Sprintf("%s%s:%d:0", playlistOpenCallbackPrefix, owner, p.ID),
+		}})
+	}
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func buildPlaylistView(tr i18n.Localizer, p playlist.Playlist, page int, owner string) (string, gotgbot.InlineKeyboardMarkup) {
+	totalPages := pageCount(len(p.Tracks), playlistPageLimit)
+	if page >= totalPages {
+		page = totalPages - 1
+	}
+	if page < 0 {
+		page = 0
+	}
+
+	back := []gotgbot.InlineKeyboardButton{{
+		Text:         tr.T(i18n.PlaylistBack),
+		CallbackData: playlistOpenCallbackPrefix + owner + ":0:0",
+	}}
+	if len(p.Tracks) == 0 {
+		text := tr.T(i18n.PlaylistEmptyNamed, p.Name)
+		return text, gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{back}}
+	}
+
+	start := page * playlistPageLimit
+	end := min(start+playlistPageLimit, len(p.Tracks))
+
+	rows := make([][]gotgbot.InlineKeyboardButton, 0, end-start+2)
+	for i, track := range p.Tracks[start:end] {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         trimButtonLabel(fmt.Sprintf("%d. %s", start+i+1, track.Title)),
+				CallbackData: searchCallbackPrefix + track.ID,
+			},
+			{
+				Text:         "❌",
+				CallbackData: fmt.Sprintf("%s%s:%d:%s:%d", playlistRemoveCallbackPrefix, owner, p.ID, track.ID, page),
+			},
+		})
+	}
+
+	if totalPages > 1 {
+		nav := make([]gotgbot.InlineKeyboardButton, 0, 2)
+		if page > 0 {
+			nav = append(nav, gotgbot.InlineKeyboardButton{
+				Text:         "⬅️",
+				CallbackData: fmt.Sprintf("%s%s:%d:%d", playlistOpenCallbackPrefix, owner, p.ID, page-1),
+			})
+		}
+		if page+1 < totalPages {
+			nav = append(nav, gotgbot.InlineKeyboardButton{
+				Text:         "➡️",
+				CallbackData: fmt.Sprintf("%s%s:%d:%d", playlistOpenCallbackPrefix, owner, p.ID, page+1),
+			})
+		}
+		rows = append(rows, nav)
+	}
+
+	rows = append(rows, append(back, gotgbot.InlineKeyboardButton{
+		Text:         tr.T(i18n.PlaylistPlayAll),
+		CallbackData: playlistPlayCallbackPrefix + owner + ":" + strconv.Itoa(p.ID),
+	}))
+
+	text := tr.N(i18n.PlaylistHeader, len(p.Tracks), p.Name, len(p.Tracks))
+	if totalPages > 1 {
+		text += tr.T(i18n.PlaylistHeaderPage, page+1, totalPages)
+	}
+	return text + ":", gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+}
+
+func parseCallbackArgs(data string, prefix string, count int) ([]string, error) {
+	if !strings.HasPrefix(data, prefix) {
+		return nil, errors.New("unexpected callback data")
+	}
+	args := strings.SplitN(strings.TrimPrefix(data, prefix), ":", count)
+	if len(args) != count {
+		return nil, errors.New("invalid callback data")
+	}
+	for _, arg := range args {
+		if strings.TrimSpace(arg) == "" {
+			return nil, errors.New("invalid callback data")
+		}
+	}
+	return args, nil
+}
@@ -0,0 +1,191 @@
+package youtube
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/playlist"
+	"music-bot-v2/internal/ratelimit"
+)
+
+const (
+	playlistCommand   = "playlist"
+	playlistPageLimit = 10
+)
+
+func (h *Handler) playlistCommand() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.playlists == nil {
+			return errors.New("playlist store is nil")
+		}
+		if ctx == nil || ctx.EffectiveMessage == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing message context")
+		}
+
+		tr := h.localizer(ctx)
+		owner := requesterID(ctx)
+		chatID := ctx.EffectiveChat.Id
+		args := strings.Fields(ctx.EffectiveMessage.GetText())
+		if len(args) > 0 {
+			args = args[1:]
+		}
+
+		sub := ""
+		if len(args) > 0 {
+			sub = strings.ToLower(args[0])
+			args = args[1:]
+		}
+
+		switch sub {
+		case "", "list", "show":
+			if len(args) == 0 {
+				return h.sendPlaylists(tr, b, chatID, owner)
+			}
+			p, err := h.playlistByPosition(owner, args[0])
+			if err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			text, keyboard := buildPlaylistView(tr, p, 0, owner)
+			_, err = b.SendMessageWithContext(h.ctx, chatID, text, &gotgbot.SendMessageOpts{ReplyMarkup: keyboard})
+			return err
+		case "new":
+			p, err := h.playlists.Create(h.ctx, owner, strings.Join(args, " "))
+			if err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistCreated, p.Name), nil)
+			return err
+		case "play":
+			if len(args) == 0 {
+				break
+			}
+			p, err := h.playlistByPosition(owner, args[0])
+			if err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			if len(p.Tracks) == 0 {
+				_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistEmpty), nil)
+				return err
+			}
+			if text, ok := h.allow(tr, ratelimit.Download, owner); !ok {
+				_, err = b.SendMessageWithContext(h.ctx, chatID, text, nil)
+				return err
+			}
+			go h.playAll(tr, h.userSettings(ctx), b, chatID, owner, p)
+			return nil
+		case "rename":
+			if len(args) < 2 {
+				break
+			}
+			p, err := h.playlistByPosition(owner, args[0])
+			if err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			name := strings.Join(args[1:], " ")
+			if err := h.playlists.Rename(h.ctx, owner, p.ID, name); err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistRenamed), nil)
+			return err
+		case "remove":
+			if len(args) < 2 {
+				break
+			}
+			p, err := h.playlistByPosition(owner, args[0])
+			if err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 || n > len(p.Tracks) {
+				return h.replyPlaylistError(tr, b, chatID, playlist.ErrTrackNotFound)
+			}
+			if _, err := h.playlists.RemoveTrack(h.ctx, owner, p.ID, p.Tracks[n-1].ID); err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistTrackRemoved), nil)
+			return err
+		case "delete":
+			if len(args) == 0 {
+				break
+			}
+			p, err := h.playlistByPosition(owner, args[0])
+			if err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			if err := h.playlists.Delete(h.ctx, owner, p.ID); err != nil {
+				return h.replyPlaylistError(tr, b, chatID, err)
+			}
+			_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistDeleted, p.Name), nil)
+			return err
+		}
+
+		_, err := b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistUsage), nil)
+		return err
+	}
+}
+
+func (h *Handler) sendPlaylists(tr i18n.Localizer, b *gotgbot.Bot, chatID int64, owner string) error {
+	list, err := h.playlists.List(h.ctx, owner)
+	if err != nil {
+		return h.replyPlaylistError(tr, b, chatID, err)
+	}
+	if len(list) == 0 {
+		_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistsNone)+"\n\n"+tr.T(i18n.PlaylistUsage), nil)
+		return err
+	}
+	_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistsTitle), &gotgbot.SendMessageOpts{
+		ReplyMarkup: buildPlaylistsKeyboard(list, owner),
+	})
+	return err
+}
+
+// playlistByPosition resolves a 1-based position as shown by /playlist.
+func (h *Handler) playlistByPosition(owner string, position string) (playlist.Playlist, error) {
+	n, err := strconv.Atoi(position)
+	if err != nil || n < 1 {
+		return playlist.Playlist{}, playlist.ErrNotFound
+	}
+	list, err := h.playlists.List(h.ctx, owner)
+	if err != nil {
+		return playlist.Playlist{}, err
+	}
+	if n > len(list) {
+		return playlist.Playlist{}, playlist.ErrNotFound
+	}
+	return list[n-1], nil
+}
+
+func (h *Handler) replyPlaylistError(tr i18n.Localizer, b *gotgbot.Bot, chatID int64, err error) error {
+	text, known := playlistErrorText(tr, err)
+	_, sendErr := b.SendMessageWithContext(h.ctx, chatID, text, nil)
+	if !known {
+		return err
+	}
+	return sendErr
+}
+
+// playlistErrorText maps playlist errors to user-facing text, reporting whether the error is an expected one.
+func playlistErrorText(tr i18n.Localizer, err error) (string, bool) {
+	switch {
+	case errors.Is(err, playlist.ErrNotFound):
+		return tr.T(i18n.PlaylistNotFound), true
+	case errors.Is(err, playlist.ErrTrackNotFound):
+		return tr.T(i18n.TrackNotFound), true
+	case errors.Is(err, playlist.ErrTrackExists):
+		return tr.T(i18n.TrackDuplicate), true
+	case errors.Is(err, playlist.ErrInvalidName):
+		return tr.T(i18n.PlaylistNameInvalid, playlist.MaxNameRunes), true
+	case errors.Is(err, playlist.ErrNameTaken):
+		return tr.T(i18n.PlaylistNameTaken), true
+	case errors.Is(err, playlist.ErrLimitReached):
+		return tr.T(i18n.PlaylistLimit, playlist.MaxPlaylists, playlist.MaxTracks), true
+	default:
+		return tr.T(i18n.PlaylistFailed), false
+	}
+}
@@ -0,0 +1,221 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/ratelimit"
+	"music-bot-v2/internal/settings"
+	youtubeapi "music-bot-v2/internal/youtube"
+)
+
+const (
+	importStartCallbackPrefix   = "ypi:"
+	importDismissCallbackPrefix = "ypc:"
+	importStopCallbackPrefix    = "yps:"
+)
+
+// sendImportPanel asks for confirmation before a pasted playlist link is delivered track by track.
+// For a watch link inside a playlist the panel also offers to send only the linked video. The tracks listed are
+// kept with the panel, confirming delivers them without fetching the playlist again.
+func (h *Handler) sendImportPanel(tr i18n.Localizer, b *gotgbot.Bot, chatID int64, owner string, playlistID string, videoID string) error {
+	tracks, err := h.music.PlaylistTracks(h.ctx, playlistID)
+	if err != nil {
+		text := tr.T(i18n.ImportFailed)
+		if errors.Is(err, youtubeapi.ErrPlaylistNotFound) {
+			text = tr.T(i18n.ImportNotFound)
+		}
+		_, sendErr := b.SendMessageWithContext(h.ctx, chatID, text, nil)
+		if errors.Is(err, youtubeapi.ErrPlaylistNotFound) {
+			return sendErr
+		}
+		return err
+	}
+	if len(tracks) == 0 {
+		_, err = b.SendMessageWithContext(h.ctx, chatID, tr.T(i18n.PlaylistEmpty), nil)
+		return err
+	}
+
+	totalSec := 0
+	for _, track := range tracks {
+		totalSec += track.DurationSec
+	}
+	text := tr.N(i18n.ImportSummary, len(tracks), len(tracks), music.FormatDuration(totalSec))
+	if len(tracks) >= music.MaxPlaylistTracks {
+		text += "\n" + tr.N(i18n.ImportTruncated, music.MaxPlaylistTracks)
+	}
+	text += "\n" + tr.T(i18n.ImportConfirm)
+
+	rows := [][]gotgbot.InlineKeyboardButton{{
+		{Text: tr.T(i18n.ImportSendAll), CallbackData: importStartCallbackPrefix},
+		{Text: tr.T(i18n.ButtonCancel), CallbackData: importDismissCallbackPrefix},
+	}}
+	if videoID != "" {
+		rows = append(rows, []gotgbot.InlineKeyboardButton{{
+			Text:         tr.T(i18n.ImportOnlyTrack),
+			CallbackData: searchCallbackPrefix + videoID,
+		}})
+	}
+	msg, err := b.SendMessageWithContext(h.ctx, chatID, text, &gotgbot.SendMessageOpts{
+		ReplyMarkup: gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows},
+	})
+	if err != nil {
+		return err
+	}
+	h.setImportPanel(chatID, msg.MessageId, importPanel{Owner: owner, PlaylistID: playlistID, Tracks: tracks})
+	return nil
+}
+
+func (h *Handler) importStartCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.music == nil {
+			return errors.New("music consumer is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveMessage == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		panel, ok := h.ownedImport(ctx)
+		if !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.ImportNotOwner))
+		}
+
+		if text, ok := h.allow(tr, ratelimit.Download, requesterID(ctx)); !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, text)
+		}
+
+		chatID := ctx.EffectiveMessage.Chat.Id
+		messageID := ctx.EffectiveMessage.MessageId
+		jobCtx, ok := h.startImport(chatID, messageID)
+		if !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.ImportRunning))
+		}
+
+		go h.importPlaylist(jobCtx, tr, h.userSettings(ctx), b, chatID, messageID, requesterID(ctx), panel.Tracks)
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	}
+}
+
+func (h *Handler) importStopCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveMessage == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		if _, ok := h.ownedImport(ctx); !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.ImportNotOwner))
+		}
+		if !h.stopImport(ctx.EffectiveMessage.Chat.Id, ctx.EffectiveMessage.MessageId) {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.ImportNothingToStop))
+		}
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.ImportStopping))
+	}
+}
+
+func (h *Handler) importDismissCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveMessage == nil {
+			return errors.New("missing callback query context")
+		}
+
+		if _, ok := h.ownedImport(ctx); !ok {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, h.localizer(ctx).T(i18n.ImportNotOwner))
+		}
+		_, _ = b.DeleteMessageWithContext(h.ctx, ctx.EffectiveMessage.Chat.Id, ctx.EffectiveMessage.MessageId, nil)
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	}
+}
+
+// ownedImport returns the import panel of the callback's message if the callback comes from the user who sent
+// the playlist link.
+func (h *Handler) ownedImport(ctx *ext.Context) (importPanel, bool) {
+	panel, ok := h.getImportPanel(ctx.EffectiveMessage.Chat.Id, ctx.EffectiveMessage.MessageId)
+	if !ok || panel.Owner != requesterID(ctx) {
+		return importPanel{}, false
+	}
+	return panel, true
+}
+
+// importPlaylist delivers the tracks of the panel in order, reporting progress in the panel message.
+func (h *Handler) importPlaylist(ctx context.Context, tr i18n.Localizer, prefs settings.Settings, b *gotgbot.Bot, chatID int64, messageID int64, requester string, tracks []music.VideoInfo) {
+	defer h.stopImport(chatID, messageID)
+
+	progress := func(text string, keyboard gotgbot.InlineKeyboardMarkup) {
+		_, _, _ = b.EditMessageTextWithContext(h.ctx, text, &gotgbot.EditMessageTextOpts{
+			ChatId:      chatID,
+			MessageId:   messageID,
+			ReplyMarkup: keyboard,
+		})
+	}
+	stopKeyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{{{
+		Text:         tr.T(i18n.ImportStop),
+		CallbackData: importStopCallbackPrefix,
+	}}}}
+	noKeyboard := gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{}}
+
+	trackIDs := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		trackIDs = append(trackIDs, track.ID)
+	}
+	sent, failed := h.deliverTracks(ctx, tr, prefs, b, chatID, requester, trackIDs, func(i int) {
+		progress(tr.T(i18n.ImportProgress, i+1, len(tracks), tracks[i].Title), stopKeyboard)
+	})
+
+	text := tr.N(i18n.ImportDone, len(tracks), sent, len(tracks))
+	if ctx.Err() != nil && h.ctx.Err() == nil {
+		text = tr.N(i18n.ImportStopped, len(tracks), sent, len(tracks))
+	}
+	if failed > 0 {
+		text += "\n" + tr.N(i18n.ImportTrackFailed, failed)
+	}
+	progress(text, noKeyboard)
+}
+
+// startImport registers an import running in the panel message, at most one per message.
+func (h *Handler) startImport(chatID int64, messageID int64) (context.Context, bool) {
+	key := messageKey(chatID, messageID)
+
+	h.importsMu.Lock()
+	defer h.importsMu.Unlock()
+	if _, ok := h.imports[key]; ok {
+		return nil, false
+	}
+	ctx, cancel := context.WithCancel(h.ctx)
+	h.imports[key] = cancel
+	return ctx, true
+}
+
+// stopImport cancels the import running in the panel message, reporting whether there was one.
+func (h *Handler) stopImport(chatID int64, messageID int64) bool {
+	key := messageKey(chatID, messageID)
+
+	h.importsMu.Lock()
+	defer h.importsMu.Unlock()
+	cancel, ok := h.imports[key]
+	if !ok {
+		return false
+	}
+	cancel()
+	delete(h.imports, key)
+	return true
+}
+
+// messageKey identifies a job by the message showing its progress.
+func messageKey(chatID int64, messageID int64) string {
+	return strconv.FormatInt(chatID, 10) + "#" + strconv.FormatInt(messageID, 10)
+}
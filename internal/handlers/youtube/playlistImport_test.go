@@ -0,0 +1,26 @@
+package youtube
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/music"
+)
+
+func TestImportPanelKeepsPlaylist(t *testing.T) {
+	h := &Handler{ctx: context.Background(), panelCache: cacher.NewMemory(cacher.PanelCacheDB, 0, 0)}
+	// Playlist IDs run up to 64 characters, more than callback data can hold next to a prefix.
+	playlistID := "PL" + strings.Repeat("x", 62)
+	tracks := []music.VideoInfo{{ID: "a", Title: "First"}, {ID: "b", Title: "Second"}}
+
+	h.setImportPanel(1, 2, importPanel{Owner: "user", PlaylistID: playlistID, Tracks: tracks})
+	panel, ok := h.getImportPanel(1, 2)
+	if !ok || panel.Owner != "user" || panel.PlaylistID != playlistID || len(panel.Tracks) != 2 || panel.Tracks[1].Title != "Second" {
+		t.Fatalf("import panel = %+v, %t", panel, ok)
+	}
+	if _, ok := h.getImportPanel(1, 3); ok {
+		t.Fatal("panel found for another message")
+	}
+}
@@ -2,25 +2,35 @@ package youtube
 
 import (
 	"log"
+	"strconv"
 )
 
-// Cache for a search query by requester, used when paging via button clicks.
-func (h *Handler) setQuery(requester string, query string) {
+// searchKey scopes a requester's search state to the chat, so a search in a group leaves the panel in the
+// private chat working. The trailing separator keeps prefix deletes from reaching other requesters' keys.
+func searchKey(chatID int64, requester string) string {
 	if requester == "" {
+		return ""
+	}
+	return strconv.FormatInt(chatID, 10) + "#" + requester + "#"
+}
+
+// Cache for a search query by search key, used when paging via button clicks.
+func (h *Handler) setQuery(key string, query string) {
+	if key == "" {
 		return
 	}
-	if err := h.queryCache.Set(h.ctx, requester, query); err != nil {
-		log.Printf("cache set query requester=%s err=%v", requester, err)
+	if err := h.queryCache.Set(h.ctx, key, query); err != nil {
+		log.Printf("cache set query key=%s err=%v", key, err)
 	}
 }
 
-func (h *Handler) getQuery(requester string) string {
-	if requester == "" {
+func (h *Handler) getQuery(key string) string {
+	if key == "" {
 		return ""
 	}
-	query, ok, err := h.queryCache.Get(h.ctx, requester)
+	query, ok, err := h.queryCache.Get(h.ctx, key)
 	if err != nil {
-		log.Printf("cache get query requester=%s err=%v", requester, err)
+		log.Printf("cache get query key=%s err=%v", key, err)
 		return ""
 	}
 	if !ok {
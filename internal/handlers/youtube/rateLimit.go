@@ -0,0 +1,59 @@
+package youtube
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/ratelimit"
+)
+
+type rateLimiter interface {
+	Allow(ctx context.Context, kind ratelimit.Kind, requester string) (bool, time.Duration)
+	AllowN(ctx context.Context, kind ratelimit.Kind, requester string, n int) (bool, time.Duration)
+}
+
+// WithRateLimiter limits how often each user may search, turn pages and download.
+func WithRateLimiter(limiter rateLimiter) Option {
+	return func(h *Handler) {
+		h.limiter = limiter
+	}
+}
+
+// allow spends a token of the requester's bucket, returning the reply to send when it is empty.
+func (h *Handler) allow(tr i18n.Localizer, kind ratelimit.Kind, requester string) (string, bool) {
+	return h.allowN(tr, kind, requester, 1)
+}
+
+// allowN spends n tokens of the requester's bucket at once, nothing is spent when it holds fewer.
+func (h *Handler) allowN(tr i18n.Localizer, kind ratelimit.Kind, requester string, n int) (string, bool) {
+	if h.limiter == nil {
+		return "", true
+	}
+	ok, retryAfter := h.limiter.AllowN(h.ctx, kind, requester, n)
+	if ok {
+		return "", true
+	}
+
+	seconds := max(int(math.Ceil(retryAfter.Seconds())), 1)
+	return tr.N(i18n.RateLimited, seconds), false
+}
+
+// waitAllow spends a token of the requester's bucket, waiting while it is empty, until ctx is done.
+func (h *Handler) waitAllow(ctx context.Context, kind ratelimit.Kind, requester string) error {
+	if h.limiter == nil {
+		return nil
+	}
+	for {
+		ok, retryAfter := h.limiter.Allow(ctx, kind, requester)
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(max(retryAfter, time.Second)):
+		}
+	}
+}
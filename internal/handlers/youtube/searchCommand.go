@@ -0,0 +1,42 @@
+package youtube
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+)
+
+const (
+	searchCommand      = "search"
+	searchShortCommand = "s"
+)
+
+// searchCommand searches the command arguments, the way to search in groups without mentioning the bot.
+func (h *Handler) searchCommand() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil || h.music == nil {
+			return errors.New("music consumer is nil")
+		}
+		if ctx == nil || ctx.EffectiveMessage == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing message context")
+		}
+
+		// Cut at the first space only, quoted operators such as channel:"Some Name" keep their spacing.
+		text := strings.TrimSpace(ctx.EffectiveMessage.GetText())
+		query := ""
+		if i := strings.IndexFunc(text, unicode.IsSpace); i >= 0 {
+			query = strings.TrimSpace(text[i:])
+		}
+		if query == "" {
+			_, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, h.localizer(ctx).T(i18n.SearchUsage), nil)
+			return err
+		}
+		return h.search(b, ctx, query)
+	}
+}
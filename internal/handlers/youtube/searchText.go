@@ -10,14 +10,19 @@ import (
 	"github.com/PaulSonOfLars/gotgbot/v2/ext"
 	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
 
+	"music-bot-v2/internal/i18n"
 	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/ratelimit"
+	youtubeapi "music-bot-v2/internal/youtube"
 )
 
 const (
 	searchCallbackPrefix     = "yt:"
 	paginationCallbackPrefix = "ytp:"
-	searchPageLimit          = 10
 	maxButtonLabelRunes      = 64
+
+	// paginationCurrentData is the current page's button in the page strip, tapping it only answers the query.
+	paginationCurrentData = paginationCallbackPrefix + "-"
 )
 
 func (h *Handler) searchText() handlers.Response {
@@ -29,95 +34,201 @@ func (h *Handler) searchText() handlers.Response {
 			return errors.New("missing message context")
 		}
 
-		requester := requesterID(ctx)
 		query := strings.TrimSpace(ctx.EffectiveMessage.GetText())
-		if query == "" {
-			_, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, "Search query is empty.", nil)
-			return err
+		// Commands without a handler, ours mistyped or other bots', are not searched.
+		if strings.HasPrefix(query, "/") {
+			return nil
 		}
-
-		go h.setQuery(requester, query)
-		h.music.ResetSearchState(h.ctx, requester)
-
-		items, total, err := h.music.SearchVideos(h.ctx, query, 0, requester)
-		if err != nil {
-			go h.clearPanelMessage(requester)
-			_, sendErr := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, "Search failed. Please try again later.", nil)
-			if sendErr != nil {
-				return sendErr
+		if !isPrivateChat(ctx.EffectiveChat) {
+			// Groups have other conversations going on, only messages meant for the bot are searched.
+			addressed, ok := addressedQuery(b, ctx.EffectiveMessage)
+			if !ok {
+				return nil
 			}
-			return err
+			query = addressed
 		}
+		return h.search(b, ctx, query)
+	}
+}
+
+// search runs the query and posts the results panel. In groups the panel can only be paged by the requester.
+func (h *Handler) search(b *gotgbot.Bot, ctx *ext.Context, query string) error {
+	tr := h.localizer(ctx)
+	prefs := h.userSettings(ctx)
+	requester := requesterID(ctx)
+	if query == "" {
+		_, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, tr.T(i18n.SearchEmpty), nil)
+		return err
+	}
+
+	playlistID, isPlaylist := youtubeapi.PlaylistIDFromURL(query)
+	videoID, isVideo := youtubeapi.VideoIDFromURL(query)
+
+	kind := ratelimit.Search
+	if isVideo && !isPlaylist {
+		kind = ratelimit.Download
+	}
+	if text, ok := h.allow(tr, kind, requester); !ok {
+		_, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, text, nil)
+		return err
+	}
+
+	if isPlaylist {
+		return h.sendImportPanel(tr, b, ctx.EffectiveChat.Id, requester, playlistID, videoID)
+	}
 
-		if len(items) == 0 {
-			go h.clearPanelMessage(requester)
-			_, err = b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, "No videos found.", nil)
+	// A pasted video link is delivered right away, a search would cost 100 quota units for nothing.
+	if isVideo {
+		if h.queue != nil {
+			return h.enqueueTrack(tr, prefs, b, ctx.EffectiveChat.Id, requester, videoID, nil)
+		}
+		if err := h.sendTrack(h.ctx, tr, b, ctx.EffectiveChat.Id, videoID, prefs, nil); err != nil {
+			_, _ = b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, tr.T(i18n.TrackFailed), nil)
 			return err
 		}
+		return nil
+	}
 
-		keyboard := buildSearchKeyboard(items, 0, total)
-		if chatID, messageID, ok := h.getPanelMessage(requester); ok {
-			_, _ = b.DeleteMessageWithContext(h.ctx, chatID, messageID, nil)
-		}
-		message, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, searchMessageText(0, total), &gotgbot.SendMessageOpts{
-			ReplyMarkup: keyboard,
-		})
-		if err == nil && message != nil {
-			go h.setPanelMessage(requester, message.Chat.Id, message.MessageId)
+	key := searchKey(ctx.EffectiveChat.Id, requester)
+	go h.setQuery(key, query)
+	h.music.ResetSearchState(h.ctx, key)
+
+	result, err := h.music.SearchVideos(h.ctx, query, 0, key, searchSettings(prefs))
+	if err != nil {
+		go h.clearPanelMessage(key)
+		_, sendErr := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, tr.T(i18n.SearchFailed), nil)
+		if sendErr != nil {
+			return sendErr
 		}
 		return err
 	}
-}
 
-func buildSearchKeyboard(items []music.VideoInfo, page int, total int) gotgbot.InlineKeyboardMarkup {
-	rows := make([][]gotgbot.InlineKeyboardButton, 0, len(items)+1)
-	for i, item := range items {
-		label := fmt.Sprintf("%d. %s", i+1, item.Title)
-		rows = append(rows, []gotgbot.InlineKeyboardButton{{
-			Text:         trimButtonLabel(label),
-			CallbackData: searchCallbackPrefix + item.ID,
-		}})
+	go h.recordSearch(requester, query, result.TotalResults)
+
+	if len(result.Items) == 0 {
+		go h.clearPanelMessage(key)
+		_, err = b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, tr.T(i18n.SearchNoResults), nil)
+		return err
+	}
+
+	owner := ""
+	if !isPrivateChat(ctx.EffectiveChat) {
+		owner = requester
+	}
+	keyboard := buildSearchKeyboard(result, 0, owner)
+	// Only the panel in this chat is replaced, a search in a group keeps the one in the private chat.
+	if chatID, messageID, ok := h.getPanelMessage(key); ok {
+		_, _ = b.DeleteMessageWithContext(h.ctx, chatID, messageID, nil)
 	}
-	if nav := buildPaginationRow(page, total); len(nav) > 0 {
-		rows = append(rows, nav)
+	message, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, searchMessageText(tr, 0, result.Pages), &gotgbot.SendMessageOpts{
+		ReplyMarkup: keyboard,
+	})
+	if err == nil && message != nil {
+		go h.setPanelMessage(key, message.Chat.Id, message.MessageId)
 	}
-	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: rows}
+	return err
 }
 
-func buildPaginationRow(page int, total int) []gotgbot.InlineKeyboardButton {
-	totalPages := pageCount(total, searchPageLimit)
-	if totalPages <= 1 {
-		return nil
+// buildSearchKeyboard lists the results; owner, if set, is the only user the navigation buttons work for.
+func buildSearchKeyboard(result music.SearchPage, page int, owner string) gotgbot.InlineKeyboardMarkup {
+	rows := make([][]gotgbot.InlineKeyboardButton, 0, len(result.Items)+2)
+	for i, item := range result.Items {
+		label := fmt.Sprintf("%d. %s", i+1, item.Label())
+		rows = append(rows, []gotgbot.InlineKeyboardButton{
+			{
+				Text:         trimButtonLabel(label),
+				CallbackData: searchCallbackPrefix + item.ID,
+			},
+			{
+				Text:         "➕",
+				CallbackData: playlistAddCallbackPrefix + item.ID,
+			},
+		})
 	}
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: append(rows, buildPaginationRows(page, result.Pages, result.LastKnown, owner)...)}
+}
 
-	hasPrev := page > 0
-	hasNext := page+1 < totalPages
-	if !hasPrev && !hasNext {
+// buildPaginationRows lays out a page strip such as 1 … 4 [5] 6 … 12, where an ellipsis jumps to the middle of
+// the pages it hides, over a row of first, previous, next and last page buttons. Until the last page is known
+// pages is an estimate, so the strip ends in an ellipsis and there is no last page button. A jump ahead goes
+// at most music.MaxPageWalk pages past the next page, whose token is known.
+func buildPaginationRows(page int, pages int, lastKnown bool, owner string) [][]gotgbot.InlineKeyboardButton {
+	if pages <= 1 {
 		return nil
 	}
 
-	row := make([]gotgbot.InlineKeyboardButton, 0, 2)
-	if hasPrev {
-		row = append(row, gotgbot.InlineKeyboardButton{
-			Text:         "⬅️",
-			CallbackData: paginationCallbackPrefix + strconv.Itoa(page-1),
-		})
+	numbers := []int{0}
+	for p := max(page-1, 1); p <= min(page+1, pages-2); p++ {
+		numbers = append(numbers, p)
 	}
-	if hasNext {
-		row = append(row, gotgbot.InlineKeyboardButton{
-			Text:         "➡️",
-			CallbackData: paginationCallbackPrefix + strconv.Itoa(page+1),
-		})
+	if lastKnown {
+		numbers = append(numbers, pages-1)
+	}
+
+	strip := make([]gotgbot.InlineKeyboardButton, 0, len(numbers)+2)
+	prev := -1
+	for _, p := range numbers {
+		strip = appendGap(strip, prev, p, page, owner)
+		strip = append(strip, pageButton(p, page, owner))
+		prev = p
+	}
+	if !lastKnown {
+		strip = appendGap(strip, prev, pages, page, owner)
+	}
+
+	nav := make([]gotgbot.InlineKeyboardButton, 0, 4)
+	// The first and last buttons are left out where they would repeat the previous and next ones.
+	if page > 1 {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "«", CallbackData: paginationData(0, owner)})
+	}
+	if page > 0 {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "⬅️", CallbackData: paginationData(page-1, owner)})
+	}
+	if page+1 < pages {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "➡️", CallbackData: paginationData(page+1, owner)})
+	}
+	if lastKnown && page+2 < pages {
+		nav = append(nav, gotgbot.InlineKeyboardButton{Text: "»", CallbackData: paginationData(pages-1, owner)})
+	}
+	return [][]gotgbot.InlineKeyboardButton{strip, nav}
+}
+
+// appendGap fills the pages between prev and next with the single page or an ellipsis.
+func appendGap(strip []gotgbot.InlineKeyboardButton, prev int, next int, page int, owner string) []gotgbot.InlineKeyboardButton {
+	switch gap := next - prev; {
+	case gap == 2:
+		return append(strip, pageButton(prev+1, page, owner))
+	case gap > 2:
+		jump := (prev + next) / 2
+		if jump > page {
+			jump = min(jump, page+1+music.MaxPageWalk)
+		}
+		return append(strip, gotgbot.InlineKeyboardButton{Text: "…", CallbackData: paginationData(jump, owner)})
+	}
+	return strip
+}
+
+// pageButton numbers the page from 1; the current page is bracketed and does nothing.
+func pageButton(p int, current int, owner string) gotgbot.InlineKeyboardButton {
+	if p == current {
+		return gotgbot.InlineKeyboardButton{Text: fmt.Sprintf("[%d]", p+1), CallbackData: paginationCurrentData}
 	}
-	return row
+	return gotgbot.InlineKeyboardButton{Text: strconv.Itoa(p + 1), CallbackData: paginationData(p, owner)}
 }
 
-func searchMessageText(page int, total int) string {
-	totalPages := pageCount(total, searchPageLimit)
-	if totalPages <= 1 {
-		return "Select a track:"
+func paginationData(page int, owner string) string {
+	data := paginationCallbackPrefix + strconv.Itoa(page)
+	if owner != "" {
+		data += ":" + owner
 	}
-	return fmt.Sprintf("Select a track (page %d/%d):", page+1, totalPages)
+	return data
+}
+
+func searchMessageText(tr i18n.Localizer, page int, pages int) string {
+	if pages <= 1 {
+		return tr.T(i18n.SearchSelect)
+	}
+	return tr.T(i18n.SearchSelectPage, page+1, pages)
 }
 
 func pageCount(total int, limit int) int {
@@ -150,3 +261,41 @@ func requesterID(ctx *ext.Context) string {
 	}
 	return ""
 }
+
+func isPrivateChat(chat *gotgbot.Chat) bool {
+	return chat != nil && chat.Type == gotgbot.ChatTypePrivate
+}
+
+// addressedQuery returns the query of a group message meant for the bot: one mentioning it, with the mention
+// removed, or a reply to one of its search panels.
+func addressedQuery(b *gotgbot.Bot, msg *gotgbot.Message) (string, bool) {
+	text := msg.Text
+	mentioned := false
+	for _, entity := range msg.ParseEntityTypes(map[string]struct{}{"mention": {}}) {
+		if strings.EqualFold(entity.Text, "@"+b.Username) {
+			mentioned = true
+			text = strings.Replace(text, entity.Text, " ", 1)
+		}
+	}
+	reply := msg.ReplyToMessage
+	replied := reply != nil && reply.From != nil && reply.From.Id == b.Id && isSearchPanel(reply)
+	if !mentioned && !replied {
+		return "", false
+	}
+	return strings.Join(strings.Fields(text), " "), true
+}
+
+// isSearchPanel reports whether the message lists search results, the only messages with add-to-playlist buttons.
+func isSearchPanel(msg *gotgbot.Message) bool {
+	if msg.ReplyMarkup == nil {
+		return false
+	}
+	for _, row := range msg.ReplyMarkup.InlineKeyboard {
+		for _, button := range row {
+			if strings.HasPrefix(button.CallbackData, playlistAddCallbackPrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
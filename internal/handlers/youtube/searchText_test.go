@@ -0,0 +1,138 @@
+package youtube
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+
+	"music-bot-v2/internal/music"
+)
+
+func TestAddressedQuery(t *testing.T) {
+	b := &gotgbot.Bot{User: gotgbot.User{Id: 42, Username: "MusicBot", IsBot: true}}
+	bot := &gotgbot.User{Id: 42, IsBot: true}
+	keyboard := buildSearchKeyboard(music.SearchPage{Items: []music.VideoInfo{{ID: "dQw4w9WgXcQ", Title: "Song"}}, Pages: 1}, 0, "")
+	panel := &gotgbot.Message{From: bot, ReplyMarkup: &keyboard}
+	mention := func(offset int64, length int64) gotgbot.MessageEntity {
+		return gotgbot.MessageEntity{Type: "mention", Offset: offset, Length: length}
+	}
+
+	tests := []struct {
+		name      string
+		msg       *gotgbot.Message
+		wantQuery string
+		wantOK    bool
+	}{
+		{
+			name:      "mention",
+			msg:       &gotgbot.Message{Text: "@musicbot  lofi beats", Entities: []gotgbot.MessageEntity{mention(0, 9)}},
+			wantQuery: "lofi beats",
+			wantOK:    true,
+		},
+		{
+			name:      "mention in the middle",
+			msg:       &gotgbot.Message{Text: "play @MusicBot jazz", Entities: []gotgbot.MessageEntity{mention(5, 9)}},
+			wantQuery: "play jazz",
+			wantOK:    true,
+		},
+		{
+			name: "another bot mentioned",
+			msg:  &gotgbot.Message{Text: "@otherbot jazz", Entities: []gotgbot.MessageEntity{mention(0, 9)}},
+		},
+		{
+			name:      "reply to a search panel",
+			msg:       &gotgbot.Message{Text: "jazz", ReplyToMessage: panel},
+			wantQuery: "jazz",
+			wantOK:    true,
+		},
+		{
+			name: "reply to a track",
+			msg:  &gotgbot.Message{Text: "nice one", ReplyToMessage: &gotgbot.Message{From: bot, Audio: &gotgbot.Audio{FileId: "file"}}},
+		},
+		{
+			name: "reply to a user",
+			msg:  &gotgbot.Message{Text: "jazz", ReplyToMessage: &gotgbot.Message{From: &gotgbot.User{Id: 7}, ReplyMarkup: panel.ReplyMarkup}},
+		},
+		{
+			name: "plain message",
+			msg:  &gotgbot.Message{Text: "jazz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, ok := addressedQuery(b, tt.msg)
+			if query != tt.wantQuery || ok != tt.wantOK {
+				t.Fatalf("addressedQuery() = %q, %t, want %q, %t", query, ok, tt.wantQuery, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSearchKeyScopesChats(t *testing.T) {
+	private, group := searchKey(100, "100"), searchKey(-500, "100")
+	if private == group {
+		t.Fatalf("private and group searches share the key %q", private)
+	}
+	// Search state is dropped by key prefix, which must not reach another requester.
+	if other := searchKey(100, "1000"); strings.HasPrefix(other, private) {
+		t.Fatalf("key %q is a prefix of %q", private, other)
+	}
+}
+
+func TestBuildPaginationRows(t *testing.T) {
+	render := func(row []gotgbot.InlineKeyboardButton) string {
+		labels := make([]string, 0, len(row))
+		for _, button := range row {
+			labels = append(labels, button.Text+"="+strings.TrimPrefix(button.CallbackData, paginationCallbackPrefix))
+		}
+		return strings.Join(labels, " ")
+	}
+
+	tests := []struct {
+		name      string
+		page      int
+		pages     int
+		lastKnown bool
+		wantStrip string
+		wantNav   string
+	}{
+		{
+			name:  "estimated pages",
+			page:  0,
+			pages: 20,
+			// The jump stops MaxPageWalk pages past the next page, the last page is not offered.
+			wantStrip: "[1]=- 2=1 …=5",
+			wantNav:   "➡️=1",
+		},
+		{
+			name:      "known last page",
+			page:      5,
+			pages:     12,
+			lastKnown: true,
+			wantStrip: "1=0 …=2 5=4 [6]=- 7=6 …=8 12=11",
+			wantNav:   "«=0 ⬅️=4 ➡️=6 »=11",
+		},
+		{
+			name:      "estimate reached",
+			page:      3,
+			pages:     4,
+			wantStrip: "1=0 2=1 3=2 [4]=-",
+			wantNav:   "«=0 ⬅️=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows := buildPaginationRows(tt.page, tt.pages, tt.lastKnown, "")
+			if len(rows) != 2 {
+				t.Fatalf("got %d rows, want 2", len(rows))
+			}
+			if strip := render(rows[0]); strip != tt.wantStrip {
+				t.Errorf("strip = %q, want %q", strip, tt.wantStrip)
+			}
+			if nav := render(rows[1]); nav != tt.wantNav {
+				t.Errorf("nav = %q, want %q", nav, tt.wantNav)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+	"music-bot-v2/internal/music"
+	"music-bot-v2/internal/settings"
+	youtubeapi "music-bot-v2/internal/youtube"
+)
+
+const (
+	settingsCommand        = "settings"
+	settingsCallbackPrefix = "cfg:"
+
+	settingPageSize   = "page"
+	settingFormat     = "format"
+	settingOrder      = "order"
+	settingThumbnails = "thumbnails"
+	settingReset      = "reset"
+)
+
+type settingsStore interface {
+	Get(ctx context.Context, user string) (settings.Settings, error)
+	Update(ctx context.Context, user string, fn func(s *settings.Settings)) (settings.Settings, error)
+	Reset(ctx context.Context, user string) (settings.Settings, error)
+}
+
+// WithSettings keeps per-user settings changed with /settings. Without it everyone gets the defaults.
+func WithSettings(store settingsStore) Option {
+	return func(h *Handler) {
+		h.settings = store
+	}
+}
+
+var (
+	formatLabels = map[settings.Format]i18n.Key{
+		settings.FormatAudio: i18n.SettingsFormatAudio,
+		settings.FormatFile:  i18n.SettingsFormatFile,
+	}
+	orderLabels = map[string]i18n.Key{
+		youtubeapi.OrderRelevance: i18n.SettingsOrderRelevance,
+		youtubeapi.OrderDate:      i18n.SettingsOrderDate,
+		youtubeapi.OrderViewCount: i18n.SettingsOrderViews,
+		youtubeapi.OrderRating:    i18n.SettingsOrderRating,
+	}
+)
+
+// userSettings returns the settings of the user behind the update, the defaults when they cannot be loaded.
+func (h *Handler) userSettings(ctx *ext.Context) settings.Settings {
+	if h.settings == nil || ctx == nil || ctx.EffectiveUser == nil {
+		return settings.Default()
+	}
+	user := strconv.FormatInt(ctx.EffectiveUser.Id, 10)
+	s, err := h.settings.Get(h.ctx, user)
+	if err != nil {
+		log.Printf("settings get user=%s err=%v", user, err)
+		return settings.Default()
+	}
+	return s
+}
+
+func searchSettings(s settings.Settings) music.SearchSettings {
+	return music.SearchSettings{PageSize: s.PageSize, Order: s.Order}
+}
+
+func (h *Handler) settingsCommand() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing message context")
+		}
+
+		tr := h.localizer(ctx)
+		_, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, tr.T(i18n.SettingsTitle), &gotgbot.SendMessageOpts{
+			ReplyMarkup: settingsKeyboard(tr, h.userSettings(ctx)),
+		})
+		return err
+	}
+}
+
+// settingsCallback moves the tapped setting to its next value and redraws the panel.
+func (h *Handler) settingsCallback() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.CallbackQuery == nil || ctx.EffectiveUser == nil || ctx.EffectiveMessage == nil {
+			return errors.New("missing callback query context")
+		}
+
+		tr := h.localizer(ctx)
+		if h.settings == nil {
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SettingsFailed))
+		}
+
+		user := strconv.FormatInt(ctx.EffectiveUser.Id, 10)
+		var updated settings.Settings
+		var err error
+		switch setting := strings.TrimPrefix(ctx.CallbackQuery.Data, settingsCallbackPrefix); setting {
+		case settingReset:
+			updated, err = h.settings.Reset(h.ctx, user)
+		case settingPageSize, settingFormat, settingOrder, settingThumbnails:
+			updated, err = h.settings.Update(h.ctx, user, func(s *settings.Settings) {
+				cycleSetting(s, setting)
+			})
+		default:
+			return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+		}
+		if err != nil {
+			_ = answerCallback(h.ctx, b, ctx.CallbackQuery, tr.T(i18n.SettingsFailed))
+			return err
+		}
+
+		_, _, _ = b.EditMessageTextWithContext(h.ctx, tr.T(i18n.SettingsTitle), &gotgbot.EditMessageTextOpts{
+			ChatId:      ctx.EffectiveMessage.Chat.Id,
+			MessageId:   ctx.EffectiveMessage.MessageId,
+			ReplyMarkup: settingsKeyboard(tr, updated),
+		})
+		return answerCallback(h.ctx, b, ctx.CallbackQuery, "")
+	}
+}
+
+func cycleSetting(s *settings.Settings, setting string) {
+	switch setting {
+	case settingPageSize:
+		s.PageSize = nextValue(settings.PageSizes, s.PageSize)
+	case settingFormat:
+		s.Format = nextValue(settings.Formats, s.Format)
+	case settingOrder:
+		s.Order = nextValue(settings.Orders, s.Order)
+	case settingThumbnails:
+		s.Thumbnails = !s.Thumbnails
+	}
+}
+
+// nextValue returns the value after current, wrapping around; an unknown current value yields the first one.
+func nextValue[T comparable](values []T, current T) T {
+	return values[(slices.Index(values, current)+1)%len(values)]
+}
+
+func settingsKeyboard(tr i18n.Localizer, s settings.Settings) gotgbot.InlineKeyboardMarkup {
+	thumbnails := tr.T(i18n.SettingsOff)
+	if s.Thumbnails {
+		thumbnails = tr.T(i18n.SettingsOn)
+	}
+	button := func(text string, setting string) []gotgbot.InlineKeyboardButton {
+		return []gotgbot.InlineKeyboardButton{{Text: text, CallbackData: settingsCallbackPrefix + setting}}
+	}
+	return gotgbot.InlineKeyboardMarkup{InlineKeyboard: [][]gotgbot.InlineKeyboardButton{
+		button(tr.T(i18n.SettingsPageSize, s.PageSize), settingPageSize),
+		button(tr.T(i18n.SettingsFormat, tr.T(formatLabels[s.Format])), settingFormat),
+		button(tr.T(i18n.SettingsOrder, tr.T(orderLabels[s.Order])), settingOrder),
+		button(tr.T(i18n.SettingsThumbnails, thumbnails), settingThumbnails),
+		button(tr.T(i18n.SettingsReset), settingReset),
+	}}
+}
@@ -0,0 +1,27 @@
+package youtube
+
+import (
+	"errors"
+
+	"github.com/PaulSonOfLars/gotgbot/v2"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext"
+	"github.com/PaulSonOfLars/gotgbot/v2/ext/handlers"
+
+	"music-bot-v2/internal/i18n"
+)
+
+const startCommand = "start"
+
+func (h *Handler) startCommand() handlers.Response {
+	return func(b *gotgbot.Bot, ctx *ext.Context) error {
+		if h == nil {
+			return errors.New("handler is nil")
+		}
+		if ctx == nil || ctx.EffectiveChat == nil {
+			return errors.New("missing message context")
+		}
+
+		_, err := b.SendMessageWithContext(h.ctx, ctx.EffectiveChat.Id, h.localizer(ctx).T(i18n.Welcome), nil)
+		return err
+	}
+}
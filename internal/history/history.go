@@ -0,0 +1,148 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxEntries is how many recent searches are kept per user.
+const MaxEntries = 10
+
+var ErrNotFound = errors.New("history entry not found")
+
+type cacherService interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+}
+
+// Entry is a search the user ran.
+type Entry struct {
+	ID    int    `json:"id"`
+	Query string `json:"query"`
+	// Results is YouTube's estimate of the total number of results.
+	Results    int       `json:"results"`
+	SearchedAt time.Time `json:"searched_at"`
+}
+
+// Store keeps the last MaxEntries searches of each user.
+type Store struct {
+	cache cacherService
+	now   func() time.Time
+	// mu keeps searches finishing at once from dropping each other's entry.
+	mu sync.Mutex
+}
+
+type userHistory struct {
+	// NextID outlives clearing, so a panel listing cleared entries cannot run searches recorded later.
+	NextID int `json:"next_id"`
+	// Entries are ordered newest first.
+	Entries []Entry `json:"entries"`
+}
+
+func NewStore(cache cacherService) *Store {
+	return &Store{cache: cache, now: time.Now}
+}
+
+// List returns the user's recent searches, newest first.
+func (s *Store) List(ctx context.Context, user string) ([]Entry, error) {
+	doc, err := s.load(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Entries, nil
+}
+
+func (s *Store) Get(ctx context.Context, user string, id int) (Entry, error) {
+	doc, err := s.load(ctx, user)
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, entry := range doc.Entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return Entry{}, ErrNotFound
+}
+
+// Add records a search. Running a query again moves it to the top instead of listing it twice, and the oldest
+// entries beyond MaxEntries are dropped.
+func (s *Store) Add(ctx context.Context, user string, query string, results int) error {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return errors.New("query is empty")
+	}
+
+	searchedAt := s.now().UTC()
+	return s.update(ctx, user, func(doc *userHistory) error {
+		entries := make([]Entry, 0, MaxEntries)
+		doc.NextID++
+		entries = append(entries, Entry{
+			ID:         doc.NextID,
+			Query:      query,
+			Results:    results,
+			SearchedAt: searchedAt,
+		})
+		for _, entry := range doc.Entries {
+			if len(entries) == MaxEntries {
+				break
+			}
+			if !strings.EqualFold(entry.Query, query) {
+				entries = append(entries, entry)
+			}
+		}
+		doc.Entries = entries
+		return nil
+	})
+}
+
+// Clear forgets the user's searches.
+func (s *Store) Clear(ctx context.Context, user string) error {
+	return s.update(ctx, user, func(doc *userHistory) error {
+		doc.Entries = nil
+		return nil
+	})
+}
+
+func (s *Store) update(ctx context.Context, user string, fn func(doc *userHistory) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load(ctx, user)
+	if err != nil {
+		return err
+	}
+	if err := fn(&doc); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, user, string(value))
+}
+
+func (s *Store) load(ctx context.Context, user string) (userHistory, error) {
+	if user == "" {
+		return userHistory{}, errors.New("user is empty")
+	}
+
+	value, ok, err := s.cache.Get(ctx, user)
+	if err != nil {
+		return userHistory{}, err
+	}
+	if !ok || value == "" {
+		return userHistory{}, nil
+	}
+
+	var doc userHistory
+	if err := json.Unmarshal([]byte(value), &doc); err != nil {
+		return userHistory{}, err
+	}
+	return doc, nil
+}
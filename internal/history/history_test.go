@@ -0,0 +1,111 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"music-bot-v2/internal/cacher"
+)
+
+// tickingStore returns a store whose clock advances a minute on every search.
+func tickingStore() *Store {
+	store := NewStore(cacher.NewMemory(cacher.HistoryDB, 0, 0))
+	now := time.Unix(1000, 0)
+	store.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return store
+}
+
+func queries(entries []Entry) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, entry.Query)
+	}
+	return out
+}
+
+func TestAddMovesRepeatedQueryToTop(t *testing.T) {
+	ctx := context.Background()
+	store := tickingStore()
+
+	for _, query := range []string{"daft  punk", "queen", "Daft Punk"} {
+		if err := store.Add(ctx, "user", query, 12); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := store.List(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := queries(entries); len(got) != 2 || got[0] != "Daft Punk" || got[1] != "queen" {
+		t.Fatalf("queries = %q, want a repeated query moved to the top", got)
+	}
+	if entries[0].Results != 12 || !entries[0].SearchedAt.After(entries[1].SearchedAt) {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	entry, err := store.Get(ctx, "user", entries[1].ID)
+	if err != nil || entry.Query != "queen" {
+		t.Fatalf("Get = %+v, %v", entry, err)
+	}
+	if _, err := store.Get(ctx, "user", 100); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing entry err = %v", err)
+	}
+	if err := store.Add(ctx, "user", "  ", 0); err == nil {
+		t.Fatal("empty query added")
+	}
+}
+
+func TestAddKeepsMaxEntries(t *testing.T) {
+	ctx := context.Background()
+	store := tickingStore()
+
+	for i := 0; i < MaxEntries+3; i++ {
+		if err := store.Add(ctx, "user", "query "+strconv.Itoa(i), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := store.List(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("kept %d entries, want %d", len(entries), MaxEntries)
+	}
+	if entries[0].Query != "query "+strconv.Itoa(MaxEntries+2) || entries[MaxEntries-1].Query != "query 3" {
+		t.Fatalf("queries = %q", queries(entries))
+	}
+}
+
+func TestClear(t *testing.T) {
+	ctx := context.Background()
+	store := tickingStore()
+
+	_ = store.Add(ctx, "user", "queen", 1)
+	_ = store.Add(ctx, "other", "abba", 1)
+	if err := store.Clear(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := store.List(ctx, "user"); len(entries) != 0 {
+		t.Fatalf("entries after clear = %+v", entries)
+	}
+	if entries, _ := store.List(ctx, "other"); len(entries) != 1 {
+		t.Fatalf("clear touched another user: %+v", entries)
+	}
+
+	// IDs keep counting, a button of the cleared history must not run a later search.
+	cleared := 1
+	_ = store.Add(ctx, "user", "abba", 1)
+	entries, _ := store.List(ctx, "user")
+	if len(entries) != 1 || entries[0].ID == cleared {
+		t.Fatalf("entries after clear = %+v, want a new ID", entries)
+	}
+	if _, err := store.Get(ctx, "user", cleared); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a cleared entry err = %v", err)
+	}
+}
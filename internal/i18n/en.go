@@ -0,0 +1,190 @@
+package i18n
+
+var en = map[Key]Message{
+	InvalidTrack:    {Other: "Invalid track."},
+	InvalidPlaylist: {Other: "Invalid playlist."},
+	InvalidPage:     {Other: "Invalid page."},
+	TrackFailed:     {Other: "Failed to load track."},
+	RateLimited: {
+		One:   "Slow down, try again in %d second.",
+		Other: "Slow down, try again in %d seconds.",
+	},
+	ButtonCancel: {Other: "✖️ Cancel"},
+
+	Welcome: {Other: "Hi! I find music on YouTube and send it as audio.\n\n" +
+		"Send me a song or artist name, or paste a YouTube video or playlist link. " +
+		"In groups use /search <query> or mention me.\n" +
+		"You can also search from any chat by typing my username followed by a query.\n\n" +
+		"Send /help for the list of commands."},
+	HelpTitle:          {Other: "Commands:"},
+	HelpAlias:          {Other: ", also /%s"},
+	CommandStart:       {Other: "Start the bot"},
+	CommandSearch:      {Other: "Search YouTube"},
+	CommandSearchUsage: {Other: "<query>"},
+	CommandPlaylist:    {Other: "Manage your playlists"},
+	CommandLanguage:    {Other: "Change the language"},
+	CommandSettings:    {Other: "Search and delivery settings"},
+	CommandHistory:     {Other: "Recent searches"},
+	CommandFavorites:   {Other: "Saved tracks"},
+	CommandHelp:        {Other: "List commands"},
+	CommandPrivateOnly: {Other: "/%s works in a private chat with @%s."},
+
+	SearchEmpty:      {Other: "Search query is empty."},
+	SearchUsage:      {Other: "Usage: /search <query>"},
+	SearchFailed:     {Other: "Search failed. Please try again later."},
+	SearchPageFailed: {Other: "Search failed. Please try again."},
+	SearchNoResults:  {Other: "No videos found."},
+	SearchExpired:    {Other: "Search expired. Send a new query."},
+	SearchNoMore:     {Other: "No more pages."},
+	SearchTooFar:     {Other: "That page is too far ahead, get closer first."},
+	SearchNotOwner:   {Other: "This search belongs to someone else."},
+	SearchSelect:     {Other: "Select a track:"},
+	SearchSelectPage: {Other: "Select a track (page %d/%d):"},
+
+	InlineLoading:    {Other: "⏳ Loading %s"},
+	InlineSearchMore: {Other: "🔎 Search more"},
+
+	DeliveryQueued:         {Other: "⏳ Queued"},
+	DeliveryQueuedPosition: {Other: "⏳ Queued, position %d"},
+	DeliveryQueueFull:      {Other: "Too many downloads right now. Please try again in a minute."},
+	DeliveryCancelled:      {Other: "Cancelled."},
+	DeliveryNothingToStop:  {Other: "Nothing to cancel."},
+	DeliveryNotOwner:       {Other: "This track was requested by someone else."},
+	StageConverting:        {Other: "⏳ Converting..."},
+	StageDownloading:       {Other: "⏳ Downloading..."},
+	StageUploading:         {Other: "⏳ Uploading..."},
+	StageSending:           {Other: "⏳ Sending..."},
+
+	PlaylistsTitle: {Other: "Your playlists:"},
+	PlaylistsNone:  {Other: "You have no playlists yet."},
+	PlaylistUsage: {Other: "Usage:\n" +
+		"/playlist — list your playlists\n" +
+		"/playlist new <name> — create a playlist\n" +
+		"/playlist show <n> — show playlist number n\n" +
+		"/playlist play <n> — send every track of playlist n\n" +
+		"/playlist rename <n> <name> — rename playlist n\n" +
+		"/playlist remove <n> <track> — remove a track from playlist n\n" +
+		"/playlist delete <n> — delete playlist n\n\n" +
+		"Tap ➕ next to a search result to add it to a playlist."},
+	PlaylistFailed:      {Other: "Playlist operation failed. Please try again later."},
+	PlaylistCreateFirst: {Other: "Create a playlist first: /playlist new <name>"},
+	PlaylistChoose:      {Other: "Add to which playlist?"},
+	PlaylistEmpty:       {Other: "Playlist is empty."},
+	PlaylistEmptyNamed:  {Other: "🎵 %s is empty. Tap ➕ next to a search result to add tracks."},
+	PlaylistSending: {
+		One:   "Sending %d track…",
+		Other: "Sending %d tracks…",
+	},
+	PlaylistSendFailed: {
+		One:   "Failed to load %d of %d track.",
+		Other: "Failed to load %d of %d tracks.",
+	},
+	PlaylistAdded:        {Other: "Added to %s."},
+	PlaylistCreated:      {Other: "Playlist %q created. Tap ➕ next to a search result to add tracks."},
+	PlaylistRenamed:      {Other: "Playlist renamed."},
+	PlaylistDeleted:      {Other: "Playlist %q deleted."},
+	PlaylistTrackRemoved: {Other: "Track removed."},
+	PlaylistHeader: {
+		One:   "🎵 %s — %d track",
+		Other: "🎵 %s — %d tracks",
+	},
+	PlaylistHeaderPage:  {Other: " (page %d/%d)"},
+	PlaylistBack:        {Other: "⬅️ Playlists"},
+	PlaylistPlayAll:     {Other: "▶️ Play all"},
+	PlaylistNotFound:    {Other: "Playlist not found. Send /playlist to see your playlists."},
+	PlaylistNotOwner:    {Other: "This playlist belongs to someone else."},
+	PlaylistNameInvalid: {Other: "Playlist name must be 1-%d characters."},
+	PlaylistNameTaken:   {Other: "You already have a playlist with this name."},
+	PlaylistLimit:       {Other: "Limit reached: up to %d playlists of %d tracks."},
+	TrackNotFound:       {Other: "Track not found."},
+	TrackDuplicate:      {Other: "Track is already in this playlist."},
+
+	ImportFailed:   {Other: "Failed to load playlist. Please try again later."},
+	ImportNotFound: {Other: "Playlist not found or private."},
+	ImportSummary: {
+		One:   "Playlist: %d track, %s total.",
+		Other: "Playlist: %d tracks, %s total.",
+	},
+	ImportTruncated: {
+		One:   "Only the first %d track is imported.",
+		Other: "Only the first %d tracks are imported.",
+	},
+	ImportConfirm:       {Other: "Send them all?"},
+	ImportSendAll:       {Other: "▶️ Send all"},
+	ImportOnlyTrack:     {Other: "🎵 Only this track"},
+	ImportRunning:       {Other: "Already sending."},
+	ImportNothingToStop: {Other: "Nothing to stop."},
+	ImportStopping:      {Other: "Stopping..."},
+	ImportStop:          {Other: "⏹ Stop"},
+	ImportProgress:      {Other: "Sending %d/%d: %s"},
+	ImportDone: {
+		One:   "Done: sent %d of %d track.",
+		Other: "Done: sent %d of %d tracks.",
+	},
+	ImportStopped: {
+		One:   "Stopped: sent %d of %d track.",
+		Other: "Stopped: sent %d of %d tracks.",
+	},
+	ImportTrackFailed: {
+		One:   "Failed to load %d track.",
+		Other: "Failed to load %d tracks.",
+	},
+	ImportNotOwner: {Other: "This playlist was sent by someone else."},
+
+	LanguageChoose: {Other: "Choose the language:"},
+	LanguageAuto:   {Other: "Automatic (from Telegram)"},
+	LanguageSet:    {Other: "Language: %s."},
+
+	SettingsTitle:          {Other: "⚙️ Settings. Tap an option to change it."},
+	SettingsFailed:         {Other: "Failed to save settings. Please try again later."},
+	SettingsPageSize:       {Other: "Results per page: %d"},
+	SettingsFormat:         {Other: "Send as: %s"},
+	SettingsFormatAudio:    {Other: "audio"},
+	SettingsFormatFile:     {Other: "file"},
+	SettingsOrder:          {Other: "Sort by: %s"},
+	SettingsOrderRelevance: {Other: "relevance"},
+	SettingsOrderDate:      {Other: "newest"},
+	SettingsOrderViews:     {Other: "views"},
+	SettingsOrderRating:    {Other: "rating"},
+	SettingsThumbnails:     {Other: "Cover art: %s"},
+	SettingsOn:             {Other: "on"},
+	SettingsOff:            {Other: "off"},
+	SettingsReset:          {Other: "↩️ Reset to defaults"},
+
+	HistoryTitle:    {Other: "🕘 Recent searches, tap one to run it again:"},
+	HistoryEmpty:    {Other: "Your search history is empty."},
+	HistoryFailed:   {Other: "Failed to load search history. Please try again later."},
+	HistoryNotFound: {Other: "This search is no longer in your history."},
+	HistoryClear:    {Other: "🗑 Clear history"},
+	HistoryCleared:  {Other: "Search history cleared."},
+	HistoryResults: {
+		One:   "~%d result",
+		Other: "~%d results",
+	},
+	HistoryJustNow: {Other: "just now"},
+	HistoryMinutesAgo: {
+		One:   "%d minute ago",
+		Other: "%d minutes ago",
+	},
+	HistoryHoursAgo: {
+		One:   "%d hour ago",
+		Other: "%d hours ago",
+	},
+	HistoryDaysAgo: {
+		One:   "%d day ago",
+		Other: "%d days ago",
+	},
+
+	FavoritesSave:     {Other: "❤️ Save"},
+	FavoritesSaved:    {Other: "❤️ Saved to /favorites."},
+	FavoritesExists:   {Other: "This track is already in your favorites."},
+	FavoritesLimit:    {Other: "You can save up to %d tracks. Remove some in /favorites first."},
+	FavoritesFailed:   {Other: "Failed to load favorites. Please try again later."},
+	FavoritesNotFound: {Other: "This track is no longer in your favorites."},
+	FavoritesEmpty:    {Other: "No saved tracks yet. Tap ❤️ Save under a track to keep it here."},
+	FavoritesHeader: {
+		One:   "❤️ Favorites — %d track",
+		Other: "❤️ Favorites — %d tracks",
+	},
+	FavoritesHeaderPage: {Other: " (page %d/%d)"},
+}
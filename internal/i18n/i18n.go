@@ -0,0 +1,142 @@
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lang is a language of the message catalogue, an ISO 639-1 code.
+type Lang string
+
+const (
+	EN Lang = "en"
+	RU Lang = "ru"
+
+	// Default is used for users whose language has no bundle.
+	Default = EN
+)
+
+// Key identifies a message in every bundle.
+type Key string
+
+// Message is a catalogue entry. Messages that depend on a count have plural forms; Other is the text of
+// messages without forms and the fallback for forms a language does not use.
+type Message struct {
+	One   string
+	Few   string
+	Many  string
+	Other string
+}
+
+var bundles = map[Lang]map[Key]Message{
+	EN: en,
+	RU: ru,
+}
+
+var names = map[Lang]string{
+	EN: "English",
+	RU: "Русский",
+}
+
+// Languages returns the languages with a bundle, the default first.
+func Languages() []Lang {
+	return []Lang{EN, RU}
+}
+
+// Name is the language's name in the language itself.
+func (l Lang) Name() string {
+	if name, ok := names[l]; ok {
+		return name
+	}
+	return string(l)
+}
+
+// Parse returns the bundle language of a language code such as "ru" or "en-US".
+func Parse(code string) (Lang, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if base, _, ok := strings.Cut(code, "-"); ok {
+		code = base
+	}
+	lang := Lang(code)
+	if _, ok := bundles[lang]; !ok {
+		return "", false
+	}
+	return lang, true
+}
+
+// Match is Parse falling back to the default language.
+func Match(code string) Lang {
+	if lang, ok := Parse(code); ok {
+		return lang
+	}
+	return Default
+}
+
+// Localizer renders messages in one language.
+type Localizer struct {
+	lang Lang
+}
+
+func New(lang Lang) Localizer {
+	if _, ok := bundles[lang]; !ok {
+		lang = Default
+	}
+	return Localizer{lang: lang}
+}
+
+func (l Localizer) Lang() Lang {
+	if l.lang == "" {
+		return Default
+	}
+	return l.lang
+}
+
+// T renders the message, formatting args into it like fmt.Sprintf.
+func (l Localizer) T(key Key, args ...any) string {
+	return format(l.message(key).Other, args)
+}
+
+// N renders the plural form of the message for count n. Without args the count is the only argument.
+func (l Localizer) N(key Key, n int, args ...any) string {
+	if len(args) == 0 {
+		args = []any{n}
+	}
+	message := l.message(key)
+	text := message.Other
+	switch pluralForm(l.Lang(), n) {
+	case formOne:
+		text = firstNonEmpty(message.One, text)
+	case formFew:
+		text = firstNonEmpty(message.Few, text)
+	case formMany:
+		text = firstNonEmpty(message.Many, text)
+	}
+	return format(text, args)
+}
+
+// message falls back to the default bundle, and to the key itself, so a missing entry never shows up empty.
+func (l Localizer) message(key Key) Message {
+	if message, ok := bundles[l.Lang()][key]; ok {
+		return message
+	}
+	if message, ok := bundles[Default][key]; ok {
+		return message
+	}
+	return Message{Other: string(key)}
+}
+
+func format(text string, args []any) string {
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
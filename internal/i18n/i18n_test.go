@@ -0,0 +1,187 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+// declaredKeys reads the Key constants from keys.go, so a key added there without messages fails the test.
+func declaredKeys(t *testing.T) []Key {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "keys.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []Key
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, value := range spec.(*ast.ValueSpec).Values {
+				lit, ok := value.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				key, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys = append(keys, Key(key))
+			}
+		}
+	}
+	if len(keys) == 0 {
+		t.Fatal("no keys found in keys.go")
+	}
+	return keys
+}
+
+func TestEveryKeyInEveryBundle(t *testing.T) {
+	keys := declaredKeys(t)
+	declared := make(map[Key]bool, len(keys))
+	for _, key := range keys {
+		if declared[key] {
+			t.Errorf("key %q declared twice", key)
+		}
+		declared[key] = true
+	}
+
+	for _, lang := range Languages() {
+		bundle, ok := bundles[lang]
+		if !ok {
+			t.Fatalf("no bundle for %s", lang)
+		}
+		for _, key := range keys {
+			if _, ok := bundle[key]; !ok {
+				t.Errorf("%s: missing key %q", lang, key)
+			}
+		}
+		for key := range bundle {
+			if !declared[key] {
+				t.Errorf("%s: key %q is not declared", lang, key)
+			}
+		}
+	}
+}
+
+var verbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+
+func TestMessagesAreConsistent(t *testing.T) {
+	for key, reference := range bundles[Default] {
+		referenceVerbs := verbPattern.FindAllString(firstNonEmpty(reference.Other, reference.One), -1)
+		for _, lang := range Languages() {
+			message := bundles[lang][key]
+			if message.plural() != reference.plural() {
+				t.Errorf("%s: %q plural=%t, %s has plural=%t", lang, key, message.plural(), Default, reference.plural())
+				continue
+			}
+
+			texts := []string{message.Other}
+			if message.plural() {
+				texts = texts[:0]
+				for _, f := range pluralForms(lang) {
+					if message.form(f) == "" {
+						t.Errorf("%s: %q lacks a plural form", lang, key)
+					}
+					texts = append(texts, message.form(f))
+				}
+			}
+			for _, text := range texts {
+				if text == "" {
+					t.Errorf("%s: %q is empty", lang, key)
+					continue
+				}
+				verbs := verbPattern.FindAllString(text, -1)
+				if len(verbs) != len(referenceVerbs) {
+					t.Errorf("%s: %q has verbs %v, %s has %v", lang, key, verbs, Default, referenceVerbs)
+					continue
+				}
+				for i := range verbs {
+					if verbs[i] != referenceVerbs[i] {
+						t.Errorf("%s: %q has verbs %v, %s has %v", lang, key, verbs, Default, referenceVerbs)
+						break
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestPluralForms(t *testing.T) {
+	tests := []struct {
+		lang Lang
+		n    int
+		want form
+	}{
+		{EN, 0, formOther},
+		{EN, 1, formOne},
+		{EN, 2, formOther},
+		{EN, 21, formOther},
+		{RU, 0, formMany},
+		{RU, 1, formOne},
+		{RU, 2, formFew},
+		{RU, 4, formFew},
+		{RU, 5, formMany},
+		{RU, 11, formMany},
+		{RU, 12, formMany},
+		{RU, 14, formMany},
+		{RU, 21, formOne},
+		{RU, 22, formFew},
+		{RU, 101, formOne},
+		{RU, 111, formMany},
+		{RU, 112, formMany},
+	}
+	for _, tt := range tests {
+		if got := pluralForm(tt.lang, tt.n); got != tt.want {
+			t.Errorf("pluralForm(%s, %d) = %d, want %d", tt.lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	ru := New(RU)
+	if got := ru.N(RateLimited, 3); got != "Не так быстро, попробуйте через 3 секунды." {
+		t.Errorf("N = %q", got)
+	}
+	if got := ru.N(PlaylistHeader, 21, "Rock", 21); got != "🎵 Rock — 21 трек" {
+		t.Errorf("N with args = %q", got)
+	}
+	if got := New(EN).N(RateLimited, 1); got != "Slow down, try again in 1 second." {
+		t.Errorf("N = %q", got)
+	}
+	if got := New("de").T(SearchSelect); got != "Select a track:" {
+		t.Errorf("unknown language = %q", got)
+	}
+	if got := ru.T("no_such_key"); got != "no_such_key" {
+		t.Errorf("missing key = %q", got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		code string
+		want Lang
+		ok   bool
+	}{
+		{"ru", RU, true},
+		{"ru-RU", RU, true},
+		{"EN-us", EN, true},
+		{"de", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Parse(tt.code)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Parse(%q) = %q, %t, want %q, %t", tt.code, got, ok, tt.want, tt.ok)
+		}
+	}
+	if Match("de") != Default {
+		t.Error("Match must fall back to the default language")
+	}
+}
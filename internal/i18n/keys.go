@@ -0,0 +1,167 @@
+package i18n
+
+// Common replies.
+const (
+	InvalidTrack    Key = "invalid_track"
+	InvalidPlaylist Key = "invalid_playlist"
+	InvalidPage     Key = "invalid_page"
+	TrackFailed     Key = "track_failed"
+	RateLimited     Key = "rate_limited"
+	ButtonCancel    Key = "button_cancel"
+)
+
+// Commands, /start and /help.
+const (
+	Welcome            Key = "welcome"
+	HelpTitle          Key = "help_title"
+	HelpAlias          Key = "help_alias"
+	CommandStart       Key = "command_start"
+	CommandSearch      Key = "command_search"
+	CommandSearchUsage Key = "command_search_usage"
+	CommandPlaylist    Key = "command_playlist"
+	CommandLanguage    Key = "command_language"
+	CommandSettings    Key = "command_settings"
+	CommandHistory     Key = "command_history"
+	CommandFavorites   Key = "command_favorites"
+	CommandHelp        Key = "command_help"
+	CommandPrivateOnly Key = "command_private_only"
+)
+
+// Search panels.
+const (
+	SearchEmpty      Key = "search_empty"
+	SearchUsage      Key = "search_usage"
+	SearchFailed     Key = "search_failed"
+	SearchPageFailed Key = "search_page_failed"
+	SearchNoResults  Key = "search_no_results"
+	SearchExpired    Key = "search_expired"
+	SearchNoMore     Key = "search_no_more"
+	SearchTooFar     Key = "search_too_far"
+	SearchNotOwner   Key = "search_not_owner"
+	SearchSelect     Key = "search_select"
+	SearchSelectPage Key = "search_select_page"
+)
+
+// Inline mode.
+const (
+	InlineLoading    Key = "inline_loading"
+	InlineSearchMore Key = "inline_search_more"
+)
+
+// Delivery queue.
+const (
+	DeliveryQueued         Key = "delivery_queued"
+	DeliveryQueuedPosition Key = "delivery_queued_position"
+	DeliveryQueueFull      Key = "delivery_queue_full"
+	DeliveryCancelled      Key = "delivery_cancelled"
+	DeliveryNothingToStop  Key = "delivery_nothing_to_stop"
+	DeliveryNotOwner       Key = "delivery_not_owner"
+	StageConverting        Key = "stage_converting"
+	StageDownloading       Key = "stage_downloading"
+	StageUploading         Key = "stage_uploading"
+	StageSending           Key = "stage_sending"
+)
+
+// Playlists.
+const (
+	PlaylistsTitle       Key = "playlists_title"
+	PlaylistsNone        Key = "playlists_none"
+	PlaylistUsage        Key = "playlist_usage"
+	PlaylistFailed       Key = "playlist_failed"
+	PlaylistCreateFirst  Key = "playlist_create_first"
+	PlaylistChoose       Key = "playlist_choose"
+	PlaylistEmpty        Key = "playlist_empty"
+	PlaylistEmptyNamed   Key = "playlist_empty_named"
+	PlaylistSending      Key = "playlist_sending"
+	PlaylistSendFailed   Key = "playlist_send_failed"
+	PlaylistAdded        Key = "playlist_added"
+	PlaylistCreated      Key = "playlist_created"
+	PlaylistRenamed      Key = "playlist_renamed"
+	PlaylistDeleted      Key = "playlist_deleted"
+	PlaylistTrackRemoved Key = "playlist_track_removed"
+	PlaylistHeader       Key = "playlist_header"
+	PlaylistHeaderPage   Key = "playlist_header_page"
+	PlaylistBack         Key = "playlist_back"
+	PlaylistPlayAll      Key = "playlist_play_all"
+	PlaylistNotFound     Key = "playlist_not_found"
+	PlaylistNotOwner     Key = "playlist_not_owner"
+	PlaylistNameInvalid  Key = "playlist_name_invalid"
+	PlaylistNameTaken    Key = "playlist_name_taken"
+	PlaylistLimit        Key = "playlist_limit"
+	TrackNotFound        Key = "track_not_found"
+	TrackDuplicate       Key = "track_duplicate"
+)
+
+// Playlist link imports.
+const (
+	ImportFailed        Key = "import_failed"
+	ImportNotFound      Key = "import_not_found"
+	ImportSummary       Key = "import_summary"
+	ImportTruncated     Key = "import_truncated"
+	ImportConfirm       Key = "import_confirm"
+	ImportSendAll       Key = "import_send_all"
+	ImportOnlyTrack     Key = "import_only_track"
+	ImportRunning       Key = "import_running"
+	ImportNothingToStop Key = "import_nothing_to_stop"
+	ImportStopping      Key = "import_stopping"
+	ImportStop          Key = "import_stop"
+	ImportProgress      Key = "import_progress"
+	ImportDone          Key = "import_done"
+	ImportStopped       Key = "import_stopped"
+	ImportTrackFailed   Key = "import_track_failed"
+	ImportNotOwner      Key = "import_not_owner"
+)
+
+// Language selection.
+const (
+	LanguageChoose Key = "language_choose"
+	LanguageAuto   Key = "language_auto"
+	LanguageSet    Key = "language_set"
+)
+
+// Settings panel.
+const (
+	SettingsTitle          Key = "settings_title"
+	SettingsFailed         Key = "settings_failed"
+	SettingsPageSize       Key = "settings_page_size"
+	SettingsFormat         Key = "settings_format"
+	SettingsFormatAudio    Key = "settings_format_audio"
+	SettingsFormatFile     Key = "settings_format_file"
+	SettingsOrder          Key = "settings_order"
+	SettingsOrderRelevance Key = "settings_order_relevance"
+	SettingsOrderDate      Key = "settings_order_date"
+	SettingsOrderViews     Key = "settings_order_views"
+	SettingsOrderRating    Key = "settings_order_rating"
+	SettingsThumbnails     Key = "settings_thumbnails"
+	SettingsOn             Key = "settings_on"
+	SettingsOff            Key = "settings_off"
+	SettingsReset          Key = "settings_reset"
+)
+
+// Search history.
+const (
+	HistoryTitle      Key = "history_title"
+	HistoryEmpty      Key = "history_empty"
+	HistoryFailed     Key = "history_failed"
+	HistoryNotFound   Key = "history_not_found"
+	HistoryClear      Key = "history_clear"
+	HistoryCleared    Key = "history_cleared"
+	HistoryResults    Key = "history_results"
+	HistoryJustNow    Key = "history_just_now"
+	HistoryMinutesAgo Key = "history_minutes_ago"
+	HistoryHoursAgo   Key = "history_hours_ago"
+	HistoryDaysAgo    Key = "history_days_ago"
+)
+
+// Favorites.
+const (
+	FavoritesSave       Key = "favorites_save"
+	FavoritesSaved      Key = "favorites_saved"
+	FavoritesExists     Key = "favorites_exists"
+	FavoritesLimit      Key = "favorites_limit"
+	FavoritesFailed     Key = "favorites_failed"
+	FavoritesNotFound   Key = "favorites_not_found"
+	FavoritesEmpty      Key = "favorites_empty"
+	FavoritesHeader     Key = "favorites_header"
+	FavoritesHeaderPage Key = "favorites_header_page"
+)
@@ -0,0 +1,61 @@
+package i18n
+
+type form int
+
+const (
+	formOther form = iota
+	formOne
+	formFew
+	formMany
+)
+
+// pluralForm implements the CLDR cardinal rules for integers.
+func pluralForm(lang Lang, n int) form {
+	if n < 0 {
+		n = -n
+	}
+	switch lang {
+	case RU:
+		// 1, 21, 101: трек; 2-4, 22-24: трека; 0, 5-20, 25-30: треков.
+		switch mod10, mod100 := n%10, n%100; {
+		case mod10 == 1 && mod100 != 11:
+			return formOne
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return formFew
+		default:
+			return formMany
+		}
+	default:
+		if n == 1 {
+			return formOne
+		}
+		return formOther
+	}
+}
+
+// pluralForms lists the forms a language uses, every plural message of its bundle must have them.
+func pluralForms(lang Lang) []form {
+	switch lang {
+	case RU:
+		return []form{formOne, formFew, formMany}
+	default:
+		return []form{formOne, formOther}
+	}
+}
+
+func (m Message) form(f form) string {
+	switch f {
+	case formOne:
+		return m.One
+	case formFew:
+		return m.Few
+	case formMany:
+		return m.Many
+	default:
+		return m.Other
+	}
+}
+
+func (m Message) plural() bool {
+	return m.One != "" || m.Few != "" || m.Many != ""
+}
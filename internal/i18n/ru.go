@@ -0,0 +1,204 @@
+package i18n
+
+var ru = map[Key]Message{
+	InvalidTrack:    {Other: "Неверный трек."},
+	InvalidPlaylist: {Other: "Неверный плейлист."},
+	InvalidPage:     {Other: "Неверная страница."},
+	TrackFailed:     {Other: "Не удалось загрузить трек."},
+	RateLimited: {
+		One:  "Не так быстро, попробуйте через %d секунду.",
+		Few:  "Не так быстро, попробуйте через %d секунды.",
+		Many: "Не так быстро, попробуйте через %d секунд.",
+	},
+	ButtonCancel: {Other: "✖️ Отмена"},
+
+	Welcome: {Other: "Привет! Я нахожу музыку на YouTube и присылаю её аудиофайлом.\n\n" +
+		"Отправьте название песни или исполнителя либо ссылку на видео или плейлист YouTube. " +
+		"В группах используйте /search <запрос> или упомяните меня.\n" +
+		"Искать можно и в любом чате: наберите моё имя пользователя и запрос.\n\n" +
+		"Список команд — /help."},
+	HelpTitle:          {Other: "Команды:"},
+	HelpAlias:          {Other: ", или /%s"},
+	CommandStart:       {Other: "Запустить бота"},
+	CommandSearch:      {Other: "Искать на YouTube"},
+	CommandSearchUsage: {Other: "<запрос>"},
+	CommandPlaylist:    {Other: "Управление плейлистами"},
+	CommandLanguage:    {Other: "Сменить язык"},
+	CommandSettings:    {Other: "Настройки поиска и отправки"},
+	CommandHistory:     {Other: "Недавние запросы"},
+	CommandFavorites:   {Other: "Сохранённые треки"},
+	CommandHelp:        {Other: "Список команд"},
+	CommandPrivateOnly: {Other: "/%s работает в личном чате с @%s."},
+
+	SearchEmpty:      {Other: "Пустой запрос."},
+	SearchUsage:      {Other: "Использование: /search <запрос>"},
+	SearchFailed:     {Other: "Поиск не удался. Попробуйте позже."},
+	SearchPageFailed: {Other: "Поиск не удался. Попробуйте ещё раз."},
+	SearchNoResults:  {Other: "Видео не найдены."},
+	SearchExpired:    {Other: "Поиск устарел. Отправьте новый запрос."},
+	SearchNoMore:     {Other: "Больше страниц нет."},
+	SearchTooFar:     {Other: "Эта страница слишком далеко, подойдите ближе."},
+	SearchNotOwner:   {Other: "Это чужой поиск."},
+	SearchSelect:     {Other: "Выберите трек:"},
+	SearchSelectPage: {Other: "Выберите трек (страница %d/%d):"},
+
+	InlineLoading:    {Other: "⏳ Загружаю %s"},
+	InlineSearchMore: {Other: "🔎 Искать ещё"},
+
+	DeliveryQueued:         {Other: "⏳ В очереди"},
+	DeliveryQueuedPosition: {Other: "⏳ В очереди, позиция %d"},
+	DeliveryQueueFull:      {Other: "Сейчас слишком много загрузок. Попробуйте через минуту."},
+	DeliveryCancelled:      {Other: "Отменено."},
+	DeliveryNothingToStop:  {Other: "Нечего отменять."},
+	DeliveryNotOwner:       {Other: "Этот трек запросил другой пользователь."},
+	StageConverting:        {Other: "⏳ Конвертирую..."},
+	StageDownloading:       {Other: "⏳ Скачиваю..."},
+	StageUploading:         {Other: "⏳ Загружаю в Telegram..."},
+	StageSending:           {Other: "⏳ Отправляю..."},
+
+	PlaylistsTitle: {Other: "Ваши плейлисты:"},
+	PlaylistsNone:  {Other: "У вас пока нет плейлистов."},
+	PlaylistUsage: {Other: "Использование:\n" +
+		"/playlist — список плейлистов\n" +
+		"/playlist new <название> — создать плейлист\n" +
+		"/playlist show <n> — показать плейлист номер n\n" +
+		"/playlist play <n> — отправить все треки плейлиста n\n" +
+		"/playlist rename <n> <название> — переименовать плейлист n\n" +
+		"/playlist remove <n> <трек> — удалить трек из плейлиста n\n" +
+		"/playlist delete <n> — удалить плейлист n\n\n" +
+		"Нажмите ➕ рядом с результатом поиска, чтобы добавить его в плейлист."},
+	PlaylistFailed:      {Other: "Не удалось выполнить операцию с плейлистом. Попробуйте позже."},
+	PlaylistCreateFirst: {Other: "Сначала создайте плейлист: /playlist new <название>"},
+	PlaylistChoose:      {Other: "В какой плейлист добавить?"},
+	PlaylistEmpty:       {Other: "Плейлист пуст."},
+	PlaylistEmptyNamed:  {Other: "🎵 %s пуст. Нажмите ➕ рядом с результатом поиска, чтобы добавить треки."},
+	PlaylistSending: {
+		One:  "Отправляю %d трек…",
+		Few:  "Отправляю %d трека…",
+		Many: "Отправляю %d треков…",
+	},
+	PlaylistSendFailed: {
+		One:  "Не удалось загрузить %d из %d трека.",
+		Few:  "Не удалось загрузить %d из %d треков.",
+		Many: "Не удалось загрузить %d из %d треков.",
+	},
+	PlaylistAdded:        {Other: "Добавлено в %s."},
+	PlaylistCreated:      {Other: "Плейлист %q создан. Нажмите ➕ рядом с результатом поиска, чтобы добавить треки."},
+	PlaylistRenamed:      {Other: "Плейлист переименован."},
+	PlaylistDeleted:      {Other: "Плейлист %q удалён."},
+	PlaylistTrackRemoved: {Other: "Трек удалён."},
+	PlaylistHeader: {
+		One:  "🎵 %s — %d трек",
+		Few:  "🎵 %s — %d трека",
+		Many: "🎵 %s — %d треков",
+	},
+	PlaylistHeaderPage:  {Other: " (страница %d/%d)"},
+	PlaylistBack:        {Other: "⬅️ Плейлисты"},
+	PlaylistPlayAll:     {Other: "▶️ Слушать все"},
+	PlaylistNotFound:    {Other: "Плейлист не найден. Отправьте /playlist, чтобы увидеть свои плейлисты."},
+	PlaylistNotOwner:    {Other: "Это чужой плейлист."},
+	PlaylistNameInvalid: {Other: "Название плейлиста должно содержать от 1 до %d символов."},
+	PlaylistNameTaken:   {Other: "У вас уже есть плейлист с таким названием."},
+	PlaylistLimit:       {Other: "Достигнут предел: не больше %d плейлистов по %d треков."},
+	TrackNotFound:       {Other: "Трек не найден."},
+	TrackDuplicate:      {Other: "Трек уже есть в этом плейлисте."},
+
+	ImportFailed:   {Other: "Не удалось загрузить плейлист. Попробуйте позже."},
+	ImportNotFound: {Other: "Плейлист не найден или закрыт."},
+	ImportSummary: {
+		One:  "Плейлист: %d трек, всего %s.",
+		Few:  "Плейлист: %d трека, всего %s.",
+		Many: "Плейлист: %d треков, всего %s.",
+	},
+	ImportTruncated: {
+		One:  "Будет импортирован только первый %d трек.",
+		Few:  "Будут импортированы только первые %d трека.",
+		Many: "Будут импортированы только первые %d треков.",
+	},
+	ImportConfirm:       {Other: "Отправить все?"},
+	ImportSendAll:       {Other: "▶️ Отправить все"},
+	ImportOnlyTrack:     {Other: "🎵 Только этот трек"},
+	ImportRunning:       {Other: "Уже отправляю."},
+	ImportNothingToStop: {Other: "Нечего останавливать."},
+	ImportStopping:      {Other: "Останавливаю..."},
+	ImportStop:          {Other: "⏹ Стоп"},
+	ImportProgress:      {Other: "Отправляю %d/%d: %s"},
+	ImportDone: {
+		One:  "Готово: отправлено %d из %d трека.",
+		Few:  "Готово: отправлено %d из %d треков.",
+		Many: "Готово: отправлено %d из %d треков.",
+	},
+	ImportStopped: {
+		One:  "Остановлено: отправлено %d из %d трека.",
+		Few:  "Остановлено: отправлено %d из %d треков.",
+		Many: "Остановлено: отправлено %d из %d треков.",
+	},
+	ImportTrackFailed: {
+		One:  "Не удалось загрузить %d трек.",
+		Few:  "Не удалось загрузить %d трека.",
+		Many: "Не удалось загрузить %d треков.",
+	},
+	ImportNotOwner: {Other: "Этот плейлист прислал другой пользователь."},
+
+	LanguageChoose: {Other: "Выберите язык:"},
+	LanguageAuto:   {Other: "Автоматически (из Telegram)"},
+	LanguageSet:    {Other: "Язык: %s."},
+
+	SettingsTitle:          {Other: "⚙️ Настройки. Нажмите на пункт, чтобы изменить его."},
+	SettingsFailed:         {Other: "Не удалось сохранить настройки. Попробуйте позже."},
+	SettingsPageSize:       {Other: "Результатов на странице: %d"},
+	SettingsFormat:         {Other: "Отправлять как: %s"},
+	SettingsFormatAudio:    {Other: "аудио"},
+	SettingsFormatFile:     {Other: "файл"},
+	SettingsOrder:          {Other: "Сортировка: %s"},
+	SettingsOrderRelevance: {Other: "по релевантности"},
+	SettingsOrderDate:      {Other: "сначала новые"},
+	SettingsOrderViews:     {Other: "по просмотрам"},
+	SettingsOrderRating:    {Other: "по рейтингу"},
+	SettingsThumbnails:     {Other: "Обложка: %s"},
+	SettingsOn:             {Other: "вкл"},
+	SettingsOff:            {Other: "выкл"},
+	SettingsReset:          {Other: "↩️ Сбросить настройки"},
+
+	HistoryTitle:    {Other: "🕘 Недавние запросы, нажмите, чтобы повторить:"},
+	HistoryEmpty:    {Other: "История поиска пуста."},
+	HistoryFailed:   {Other: "Не удалось загрузить историю поиска. Попробуйте позже."},
+	HistoryNotFound: {Other: "Этого запроса уже нет в истории."},
+	HistoryClear:    {Other: "🗑 Очистить историю"},
+	HistoryCleared:  {Other: "История поиска очищена."},
+	HistoryResults: {
+		One:  "~%d результат",
+		Few:  "~%d результата",
+		Many: "~%d результатов",
+	},
+	HistoryJustNow: {Other: "только что"},
+	HistoryMinutesAgo: {
+		One:  "%d минуту назад",
+		Few:  "%d минуты назад",
+		Many: "%d минут назад",
+	},
+	HistoryHoursAgo: {
+		One:  "%d час назад",
+		Few:  "%d часа назад",
+		Many: "%d часов назад",
+	},
+	HistoryDaysAgo: {
+		One:  "%d день назад",
+		Few:  "%d дня назад",
+		Many: "%d дней назад",
+	},
+
+	FavoritesSave:     {Other: "❤️ Сохранить"},
+	FavoritesSaved:    {Other: "❤️ Сохранено в /favorites."},
+	FavoritesExists:   {Other: "Этот трек уже в избранном."},
+	FavoritesLimit:    {Other: "Можно сохранить до %d треков. Сначала удалите лишние в /favorites."},
+	FavoritesFailed:   {Other: "Не удалось загрузить избранное. Попробуйте позже."},
+	FavoritesNotFound: {Other: "Этого трека уже нет в избранном."},
+	FavoritesEmpty:    {Other: "Сохранённых треков пока нет. Нажмите ❤️ Сохранить под треком, чтобы он появился здесь."},
+	FavoritesHeader: {
+		One:  "❤️ Избранное — %d трек",
+		Few:  "❤️ Избранное — %d трека",
+		Many: "❤️ Избранное — %d треков",
+	},
+	FavoritesHeaderPage: {Other: " (страница %d/%d)"},
+}
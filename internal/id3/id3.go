@@ -0,0 +1,164 @@
+package id3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/http"
+	"unicode/utf16"
+)
+
+const (
+	headerSize  = 10
+	footerSize  = 10
+	v1TagSize   = 128
+	flagFooter  = 0x10
+	maxTagSize  = 1<<28 - 1
+	pictureType = 0x03 // front cover
+
+	encodingISO88591 = 0x00
+	encodingUTF16    = 0x01
+)
+
+var ErrTagTooLarge = errors.New("id3 tag is too large")
+
+// Tag is the metadata written into a file. Empty fields produce no frame.
+type Tag struct {
+	Title   string
+	Artist  string
+	Album   string
+	Year    string
+	Comment string
+	// URL is written as a WXXX user-defined link frame.
+	URL string
+	// Cover is the front cover image; its MIME type is detected from the data.
+	Cover []byte
+}
+
+// Encode renders the tag as an ID3v2.3 tag.
+func Encode(tag Tag) ([]byte, error) {
+	var frames bytes.Buffer
+	writeTextFrame(&frames, "TIT2", tag.Title)
+	writeTextFrame(&frames, "TPE1", tag.Artist)
+	writeTextFrame(&frames, "TALB", tag.Album)
+	writeTextFrame(&frames, "TYER", tag.Year)
+	if tag.Comment != "" {
+		body := []byte{encodingUTF16, 'e', 'n', 'g'}
+		body = append(body, encodeUTF16("")...)
+		body = append(body, encodeUTF16(tag.Comment)...)
+		writeFrame(&frames, "COMM", body)
+	}
+	if tag.URL != "" {
+		body := []byte{encodingISO88591, 0}
+		body = append(body, tag.URL...)
+		writeFrame(&frames, "WXXX", body)
+	}
+	if len(tag.Cover) > 0 {
+		body := []byte{encodingISO88591}
+		body = append(body, http.DetectContentType(tag.Cover)...)
+		body = append(body, 0, pictureType, 0)
+		body = append(body, tag.Cover...)
+		writeFrame(&frames, "APIC", body)
+	}
+
+	if frames.Len() > maxTagSize {
+		return nil, ErrTagTooLarge
+	}
+	out := make([]byte, 0, headerSize+frames.Len())
+	out = append(out, 'I', 'D', '3', 3, 0, 0)
+	out = append(out, syncsafe(uint32(frames.Len()))...)
+	return append(out, frames.Bytes()...), nil
+}
+
+// Rewrite returns the file with its ID3v2 and ID3v1 tags replaced by tag, and the resulting size.
+// The audio is read from src lazily, only the new tag is held in memory.
+func Rewrite(src io.ReaderAt, size int64, tag Tag) (io.Reader, int64, error) {
+	start, err := audioStart(src, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	end, err := audioEnd(src, start, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	encoded, err := Encode(tag)
+	if err != nil {
+		return nil, 0, err
+	}
+	audio := io.NewSectionReader(src, start, end-start)
+	return io.MultiReader(bytes.NewReader(encoded), audio), int64(len(encoded)) + end - start, nil
+}
+
+// audioStart skips every ID3v2 tag at the start of the file, some converters prepend more than one.
+func audioStart(src io.ReaderAt, size int64) (int64, error) {
+	var offset int64
+	header := make([]byte, headerSize)
+	for offset+headerSize <= size {
+		if _, err := src.ReadAt(header, offset); err != nil {
+			return 0, err
+		}
+		if !bytes.Equal(header[:3], []byte("ID3")) || header[3] == 0xFF || header[4] == 0xFF {
+			break
+		}
+		tagSize := int64(unsyncsafe(header[6:10])) + headerSize
+		if header[5]&flagFooter != 0 {
+			tagSize += footerSize
+		}
+		if offset+tagSize > size {
+			return 0, errors.New("id3 tag exceeds file size")
+		}
+		offset += tagSize
+	}
+	return offset, nil
+}
+
+// audioEnd excludes a trailing ID3v1 tag.
+func audioEnd(src io.ReaderAt, start int64, size int64) (int64, error) {
+	if size-start < v1TagSize {
+		return size, nil
+	}
+	marker := make([]byte, 3)
+	if _, err := src.ReadAt(marker, size-v1TagSize); err != nil {
+		return 0, err
+	}
+	if bytes.Equal(marker, []byte("TAG")) {
+		return size - v1TagSize, nil
+	}
+	return size, nil
+}
+
+func writeTextFrame(w *bytes.Buffer, id string, text string) {
+	if text == "" {
+		return
+	}
+	body := append([]byte{encodingUTF16}, encodeUTF16(text)...)
+	writeFrame(w, id, body)
+}
+
+func writeFrame(w *bytes.Buffer, id string, body []byte) {
+	w.WriteString(id)
+	_ = binary.Write(w, binary.BigEndian, uint32(len(body)))
+	w.Write([]byte{0, 0})
+	w.Write(body)
+}
+
+// encodeUTF16 encodes text as null-terminated UTF-16 with a byte order mark, the only Unicode encoding in v2.3.
+func encodeUTF16(text string) []byte {
+	units := utf16.Encode([]rune(text))
+	out := make([]byte, 0, 2+len(units)*2+2)
+	out = append(out, 0xFF, 0xFE)
+	for _, unit := range units {
+		out = append(out, byte(unit), byte(unit>>8))
+	}
+	return append(out, 0, 0)
+}
+
+func syncsafe(n uint32) []byte {
+	return []byte{byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+}
+
+func unsyncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
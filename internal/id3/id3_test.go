@@ -0,0 +1,104 @@
+package id3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"unicode/utf16"
+)
+
+var audio = bytes.Repeat([]byte{0xFF, 0xFB, 0x90, 0x64}, 64)
+
+// frames parses an ID3v2.3 tag into frame bodies by ID.
+func frames(t *testing.T, data []byte) map[string][]byte {
+	t.Helper()
+	if !bytes.HasPrefix(data, []byte("ID3\x03\x00\x00")) {
+		t.Fatalf("missing ID3v2.3 header: % x", data[:10])
+	}
+	end := headerSize + int(unsyncsafe(data[6:10]))
+	result := make(map[string][]byte)
+	for offset := headerSize; offset+10 <= end; {
+		id := string(data[offset : offset+4])
+		size := int(binary.BigEndian.Uint32(data[offset+4 : offset+8]))
+		result[id] = data[offset+10 : offset+10+size]
+		offset += 10 + size
+	}
+	return result
+}
+
+func decodeText(t *testing.T, body []byte) string {
+	t.Helper()
+	if body[0] != encodingUTF16 || body[1] != 0xFF || body[2] != 0xFE {
+		t.Fatalf("unexpected text encoding: % x", body[:3])
+	}
+	raw := body[3 : len(body)-2]
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = uint16(raw[2*i]) | uint16(raw[2*i+1])<<8
+	}
+	return string(utf16.Decode(units))
+}
+
+func TestRewriteReplacesTags(t *testing.T) {
+	oldTag, err := Encode(Tag{Title: "garbage", Comment: "converted by example.com"})
+	if err != nil {
+		t.Fatalf("Encode error: %v", err)
+	}
+	v1 := append([]byte("TAG"), make([]byte, v1TagSize-3)...)
+	file := append(append(append([]byte{}, oldTag...), audio...), v1...)
+
+	cover := []byte("\xFF\xD8\xFF\xE0\x00\x10JFIF\x00")
+	reader, size, err := Rewrite(bytes.NewReader(file), int64(len(file)), Tag{
+		Title:   "Песня",
+		Artist:  "Artist",
+		Album:   "Album",
+		Year:    "2020",
+		Comment: "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		URL:     "https://www.youtube.com/watch?v=dQw4w9WgXcQ",
+		Cover:   cover,
+	})
+	if err != nil {
+		t.Fatalf("Rewrite error: %v", err)
+	}
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("read error: %v", err)
+	}
+	if int64(len(out)) != size {
+		t.Fatalf("size = %d, read %d bytes", size, len(out))
+	}
+	if !bytes.HasSuffix(out, audio) {
+		t.Fatal("audio frames were not preserved or the ID3v1 tag was kept")
+	}
+
+	got := frames(t, out)
+	for id, want := range map[string]string{"TIT2": "Песня", "TPE1": "Artist", "TALB": "Album", "TYER": "2020"} {
+		if text := decodeText(t, got[id]); text != want {
+			t.Errorf("%s = %q, want %q", id, text, want)
+		}
+	}
+	if !bytes.HasSuffix(got["WXXX"], []byte("https://www.youtube.com/watch?v=dQw4w9WgXcQ")) {
+		t.Errorf("WXXX = %q", got["WXXX"])
+	}
+	if !bytes.HasPrefix(got["APIC"], []byte("\x00image/jpeg\x00\x03\x00")) || !bytes.HasSuffix(got["APIC"], cover) {
+		t.Errorf("APIC = % x", got["APIC"])
+	}
+	if _, ok := got["COMM"]; !ok {
+		t.Error("missing COMM frame")
+	}
+}
+
+func TestRewriteUntaggedFile(t *testing.T) {
+	reader, size, err := Rewrite(bytes.NewReader(audio), int64(len(audio)), Tag{Title: "Song"})
+	if err != nil {
+		t.Fatalf("Rewrite error: %v", err)
+	}
+	out, _ := io.ReadAll(reader)
+	if int64(len(out)) != size || !bytes.HasSuffix(out, audio) {
+		t.Fatalf("unexpected output of %d bytes", len(out))
+	}
+	if text := decodeText(t, frames(t, out)["TIT2"]); text != "Song" {
+		t.Fatalf("TIT2 = %q", text)
+	}
+}
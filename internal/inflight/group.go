@@ -0,0 +1,50 @@
+package inflight
+
+import (
+	"context"
+	"sync"
+)
+
+// Group coalesces concurrent calls with the same key into one execution, like singleflight, and tells the
+// caller whether it ran the function itself or received the result of another caller.
+type Group[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*call[T]
+}
+
+type call[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Do runs fn unless a call with the key is in flight, in which case it waits for that call's result.
+// A waiter gives up when ctx is done; the running call is not affected.
+func (g *Group[T]) Do(ctx context.Context, key string, fn func() (T, error)) (T, bool, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call[T])
+	}
+	if c, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-c.done:
+			return c.val, false, c.err
+		case <-ctx.Done():
+			var zero T
+			return zero, false, ctx.Err()
+		}
+	}
+	c := &call[T]{done: make(chan struct{})}
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(c.done)
+	}()
+	c.val, c.err = fn()
+	return c.val, true, c.err
+}
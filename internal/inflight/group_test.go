@@ -0,0 +1,86 @@
+package inflight
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestGroupCoalescesCalls(t *testing.T) {
+	var group Group[string]
+	var runs atomic.Int32
+	release := make(chan struct{})
+
+	const callers = 10
+	var executed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val, ran, err := group.Do(context.Background(), "track", func() (string, error) {
+				runs.Add(1)
+				<-release
+				return "file-id", nil
+			})
+			if err != nil || val != "file-id" {
+				t.Errorf("Do = %q, %v", val, err)
+			}
+			if ran {
+				executed.Add(1)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 || executed.Load() != 1 {
+		t.Fatalf("expected one execution, got runs=%d executed=%d", runs.Load(), executed.Load())
+	}
+
+	if _, ran, _ := group.Do(context.Background(), "track", func() (string, error) { return "", nil }); !ran {
+		t.Fatal("a finished call must not be reused")
+	}
+}
+
+func TestRedisLock(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	lock := NewRedisLock(client)
+	ctx := context.Background()
+
+	release, ok, err := lock.TryLock(ctx, "convert#track", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("TryLock = %v, %v", ok, err)
+	}
+	if _, ok, _ := lock.TryLock(ctx, "convert#track", time.Minute); ok {
+		t.Fatal("lock acquired twice")
+	}
+	release()
+	if _, ok, _ := lock.TryLock(ctx, "convert#track", time.Minute); !ok {
+		t.Fatal("lock not released")
+	}
+
+	server.FastForward(2 * time.Minute)
+	staleRelease, ok, _ := lock.TryLock(ctx, "convert#other", time.Second)
+	if !ok {
+		t.Fatal("expected lock")
+	}
+	server.FastForward(2 * time.Second)
+	if _, ok, _ := lock.TryLock(ctx, "convert#other", time.Minute); !ok {
+		t.Fatal("expired lock not acquired")
+	}
+	staleRelease()
+	if _, ok, _ := lock.TryLock(ctx, "convert#other", time.Minute); ok {
+		t.Fatal("stale release deleted another holder's lock")
+	}
+}
@@ -0,0 +1,54 @@
+package inflight
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseScript deletes the lock only if it still holds our token, an expired lock may belong to someone else.
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLock is a lease-based lock shared by every replica using the same Redis database.
+type RedisLock struct {
+	client *redis.Client
+}
+
+func NewRedisLock(client *redis.Client) *RedisLock {
+	return &RedisLock{client: client}
+}
+
+// TryLock acquires the key for at most ttl without waiting, returning the function that releases it.
+func (l *RedisLock) TryLock(ctx context.Context, key string, ttl time.Duration) (func(), bool, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, false, err
+	}
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	release := func() {
+		// The caller's context may be done by now, the lock should still be released.
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = releaseScript.Run(ctx, l.client, []string{key}, token).Err()
+	}
+	return release, true, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
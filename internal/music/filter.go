@@ -0,0 +1,287 @@
+package music
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"music-bot-v2/internal/youtube"
+)
+
+// API videoDuration buckets: short is under 4 minutes, long is over 20 minutes.
+const (
+	shortVideoMax = 4 * time.Minute
+	longVideoMin  = 20 * time.Minute
+
+	shortsMaxDuration = time.Minute
+)
+
+var channelIDPattern = regexp.MustCompile(`^UC[0-9A-Za-z_-]{22}$`)
+
+// SearchFilter is a search query with its operators parsed out.
+//
+// Supported operators:
+//
+//	dur:<8m dur:>=2m dur:2m-8m dur:short|medium|long  duration bounds
+//	sort:views|date|rating|title|relevance             result ordering
+//	after:2020 after:2020-05 after:2020-05-17          published after (before: likewise)
+//	channel:Name channel:"Some Name" channel:@handle   restrict to a channel
+//	cat:music                                          only the Music category
+//	-live -shorts                                      drop broadcasts and Shorts
+//
+// Bounds are inclusive; a zero MaxDuration means no upper bound.
+type SearchFilter struct {
+	Text string
+
+	MinDuration     time.Duration
+	MaxDuration     time.Duration
+	Order           string
+	PublishedAfter  time.Time
+	PublishedBefore time.Time
+	Channel         string
+	// ChannelID is set when Channel is a channel ID or has been resolved as a handle.
+	ChannelID     string
+	MusicOnly     bool
+	ExcludeLive   bool
+	ExcludeShorts bool
+}
+
+// ParseQuery splits the operators out of a query; words that are not valid operators stay in Text.
+func ParseQuery(query string) SearchFilter {
+	var filter SearchFilter
+	var text []string
+	for _, token := range tokenizeQuery(query) {
+		if !filter.apply(token) {
+			text = append(text, token)
+		}
+	}
+	filter.Text = strings.Join(text, " ")
+	return filter
+}
+
+func (f *SearchFilter) apply(token string) bool {
+	switch strings.ToLower(token) {
+	case "-live":
+		f.ExcludeLive = true
+		return true
+	case "-shorts":
+		f.ExcludeShorts = true
+		return true
+	}
+
+	name, value, ok := strings.Cut(token, ":")
+	if !ok || value == "" {
+		return false
+	}
+	value = strings.Trim(value, `"`)
+
+	switch strings.ToLower(name) {
+	case "dur", "duration":
+		return f.applyDuration(strings.ToLower(value))
+	case "sort", "order":
+		order, ok := parseOrder(strings.ToLower(value))
+		if ok {
+			f.Order = order
+		}
+		return ok
+	case "after":
+		date, ok := parseDate(value)
+		if ok {
+			f.PublishedAfter = date
+		}
+		return ok
+	case "before":
+		date, ok := parseDate(value)
+		if ok {
+			f.PublishedBefore = date
+		}
+		return ok
+	case "channel":
+		f.Channel = strings.TrimSpace(value)
+		if channelIDPattern.MatchString(f.Channel) {
+			f.ChannelID = f.Channel
+		}
+		return f.Channel != ""
+	case "cat", "category":
+		if strings.ToLower(value) != "music" {
+			return false
+		}
+		f.MusicOnly = true
+		return true
+	}
+	return false
+}
+
+func (f *SearchFilter) applyDuration(value string) bool {
+	switch value {
+	case "short":
+		f.MinDuration, f.MaxDuration = 0, shortVideoMax-time.Second
+		return true
+	case "medium":
+		f.MinDuration, f.MaxDuration = shortVideoMax, longVideoMin
+		return true
+	case "long":
+		f.MinDuration, f.MaxDuration = longVideoMin+time.Second, 0
+		return true
+	}
+
+	for _, op := range []string{"<=", ">=", "<", ">"} {
+		rest, ok := strings.CutPrefix(value, op)
+		if !ok {
+			continue
+		}
+		d, ok := parseFilterDuration(rest)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "<=":
+			f.MaxDuration = d
+		case "<":
+			f.MaxDuration = d - time.Second
+		case ">=":
+			f.MinDuration = d
+		case ">":
+			f.MinDuration = d + time.Second
+		}
+		return f.MaxDuration >= 0
+	}
+
+	if from, to, ok := strings.Cut(value, "-"); ok {
+		minDuration, okMin := parseFilterDuration(from)
+		maxDuration, okMax := parseFilterDuration(to)
+		if !okMin || !okMax || minDuration > maxDuration {
+			return false
+		}
+		f.MinDuration, f.MaxDuration = minDuration, maxDuration
+		return true
+	}
+	return false
+}
+
+// SearchOptions maps the filter onto search.list parameters. videoDuration is only set when one API bucket
+// covers the whole requested range, the exact bounds are enforced by Match.
+func (f SearchFilter) SearchOptions(pageToken string) youtube.SearchOptions {
+	opts := youtube.SearchOptions{
+		PageToken:       pageToken,
+		Order:           f.Order,
+		PublishedAfter:  f.PublishedAfter,
+		PublishedBefore: f.PublishedBefore,
+		ChannelID:       f.ChannelID,
+	}
+	if f.MusicOnly {
+		opts.CategoryID = youtube.MusicCategoryID
+	}
+
+	switch {
+	case f.MaxDuration > 0 && f.MaxDuration < shortVideoMax:
+		opts.VideoDuration = youtube.VideoDurationShort
+	case f.MinDuration > longVideoMin:
+		opts.VideoDuration = youtube.VideoDurationLong
+	case f.MinDuration >= shortVideoMax && f.MaxDuration > 0 && f.MaxDuration <= longVideoMin:
+		opts.VideoDuration = youtube.VideoDurationMedium
+	}
+	return opts
+}
+
+// Match applies the conditions the API has no parameter for to a fetched video.
+func (f SearchFilter) Match(video youtube.Video) bool {
+	live := video.LiveBroadcastContent == "live" || video.LiveBroadcastContent == "upcoming"
+	if f.ExcludeLive && (live || video.Duration == 0) {
+		return false
+	}
+	if f.ExcludeShorts && (video.Duration > 0 && video.Duration <= shortsMaxDuration ||
+		strings.Contains(strings.ToLower(video.Title), "#shorts")) {
+		return false
+	}
+	// Broadcasts have no duration yet, so duration bounds do not apply to them.
+	if video.Duration > 0 {
+		if video.Duration < f.MinDuration {
+			return false
+		}
+		if f.MaxDuration > 0 && video.Duration > f.MaxDuration {
+			return false
+		}
+	}
+	if f.Channel != "" && f.ChannelID == "" {
+		if !strings.Contains(strings.ToLower(video.ChannelTitle), strings.ToLower(f.Channel)) {
+			return false
+		}
+	}
+	return true
+}
+
+// channelHandle returns the channel operator value as a handle, if it can be one.
+func (f SearchFilter) channelHandle() (string, bool) {
+	if f.Channel == "" || f.ChannelID != "" || strings.ContainsFunc(f.Channel, unicode.IsSpace) {
+		return "", false
+	}
+	return f.Channel, true
+}
+
+func parseOrder(value string) (string, bool) {
+	switch value {
+	case "views", "viewcount":
+		return youtube.OrderViewCount, true
+	case "date", "new", "newest":
+		return youtube.OrderDate, true
+	case "rating":
+		return youtube.OrderRating, true
+	case "title":
+		return youtube.OrderTitle, true
+	case "relevance":
+		return youtube.OrderRelevance, true
+	}
+	return "", false
+}
+
+func parseDate(value string) (time.Time, bool) {
+	for _, layout := range []string{"2006", "2006-01", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseFilterDuration accepts Go durations like "8m" or "1h30m", and plain numbers as minutes.
+func parseFilterDuration(value string) (time.Duration, bool) {
+	if minutes, err := strconv.Atoi(value); err == nil {
+		if minutes < 0 {
+			return 0, false
+		}
+		return time.Duration(minutes) * time.Minute, true
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, false
+	}
+	return d, true
+}
+
+// tokenizeQuery splits on whitespace, keeping double-quoted parts such as channel:"Some Name" in one token.
+func tokenizeQuery(query string) []string {
+	var tokens []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !quoted:
+			if current.Len() > 0 {
+				tokens = append(tokens, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, current.String())
+	}
+	return tokens
+}
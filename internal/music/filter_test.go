@@ -0,0 +1,82 @@
+package music
+
+import (
+	"testing"
+	"time"
+
+	"music-bot-v2/internal/youtube"
+)
+
+func TestParseQuery(t *testing.T) {
+	filter := ParseQuery(`daft punk dur:<8m sort:views after:2020 channel:"Daft Punk" -live -shorts cat:music foo:bar`)
+
+	if filter.Text != "daft punk foo:bar" {
+		t.Errorf("Text = %q", filter.Text)
+	}
+	if filter.MaxDuration != 8*time.Minute-time.Second || filter.MinDuration != 0 {
+		t.Errorf("duration bounds = %v..%v", filter.MinDuration, filter.MaxDuration)
+	}
+	if filter.Order != youtube.OrderViewCount {
+		t.Errorf("Order = %q", filter.Order)
+	}
+	if !filter.PublishedAfter.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("PublishedAfter = %v", filter.PublishedAfter)
+	}
+	if filter.Channel != "Daft Punk" || filter.ChannelID != "" {
+		t.Errorf("Channel = %q, ChannelID = %q", filter.Channel, filter.ChannelID)
+	}
+	if !filter.ExcludeLive || !filter.ExcludeShorts || !filter.MusicOnly {
+		t.Errorf("flags = live:%v shorts:%v music:%v", filter.ExcludeLive, filter.ExcludeShorts, filter.MusicOnly)
+	}
+}
+
+func TestParseQueryKeepsInvalidOperators(t *testing.T) {
+	filter := ParseQuery("song dur:<abc sort:loud after:yesterday")
+	if filter.Text != "song dur:<abc sort:loud after:yesterday" {
+		t.Errorf("Text = %q", filter.Text)
+	}
+}
+
+func TestSearchFilterOptions(t *testing.T) {
+	tests := []struct {
+		query    string
+		duration string
+	}{
+		{"dur:<3m", youtube.VideoDurationShort},
+		{"dur:<8m", youtube.VideoDurationAny},
+		{"dur:5m-15m", youtube.VideoDurationMedium},
+		{"dur:>30m", youtube.VideoDurationLong},
+		{"dur:long", youtube.VideoDurationLong},
+	}
+	for _, tt := range tests {
+		if got := ParseQuery(tt.query).SearchOptions("").VideoDuration; got != tt.duration {
+			t.Errorf("%q videoDuration = %q, want %q", tt.query, got, tt.duration)
+		}
+	}
+
+	opts := ParseQuery("channel:UC_x5XG1OV2P6uZZ5FSM9Ttw cat:music").SearchOptions("token")
+	if opts.ChannelID != "UC_x5XG1OV2P6uZZ5FSM9Ttw" || opts.CategoryID != youtube.MusicCategoryID || opts.PageToken != "token" {
+		t.Errorf("options = %+v", opts)
+	}
+}
+
+func TestSearchFilterMatch(t *testing.T) {
+	filter := ParseQuery("q dur:>=2m dur:<=10m -live -shorts channel:punk")
+	tests := []struct {
+		name  string
+		video youtube.Video
+		want  bool
+	}{
+		{"track", youtube.Video{Duration: 4 * time.Minute, ChannelTitle: "Daft Punk"}, true},
+		{"too long", youtube.Video{Duration: time.Hour, ChannelTitle: "Daft Punk"}, false},
+		{"too short", youtube.Video{Duration: 90 * time.Second, ChannelTitle: "Daft Punk"}, false},
+		{"live", youtube.Video{LiveBroadcastContent: "live", ChannelTitle: "Daft Punk"}, false},
+		{"shorts tag", youtube.Video{Duration: 3 * time.Minute, Title: "clip #Shorts", ChannelTitle: "Daft Punk"}, false},
+		{"other channel", youtube.Video{Duration: 4 * time.Minute, ChannelTitle: "Queen"}, false},
+	}
+	for _, tt := range tests {
+		if got := filter.Match(tt.video); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
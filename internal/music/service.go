@@ -4,23 +4,50 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"strconv"
 	"strings"
+	"time"
 
-	"music-bot-v2/internal/cacher"
 	"music-bot-v2/internal/youtube"
 )
 
+const (
+	// MaxPlaylistTracks caps how many tracks of a YouTube playlist are imported.
+	MaxPlaylistTracks = 200
+	// DefaultPageSize is the number of search results per page unless SearchSettings ask for another.
+	DefaultPageSize = 10
+	// maxSearchResults caps the pages offered for a search: totalResults is an estimate that runs into millions
+	// while results dry up after a few hundred.
+	maxSearchResults = 200
+	// MaxPageWalk is the most pages one request may walk to find a page token, each walked page costs a search.
+	MaxPageWalk = 4
+
+	// lastPageKey stores the last page of a search next to its page tokens.
+	lastPageKey = "last"
+)
+
+var (
+	// ErrPageNotFound is returned for a search page past the last one.
+	ErrPageNotFound = errors.New("search page not found")
+	// ErrPageTooFar is returned for a page more than MaxPageWalk pages away from a known page token.
+	ErrPageTooFar = errors.New("search page too far")
+)
+
 type cacherService interface {
 	Get(ctx context.Context, key string) (string, bool, error)
 	Set(ctx context.Context, key, value string) error
+	Delete(ctx context.Context, key string) error
 	DeletePrefix(ctx context.Context, prefix string) error
 }
 
 type youtubeClient interface {
-	Search(ctx context.Context, query string, pageToken string) ([]string, youtube.Pagination, error)
-	Videos(ctx context.Context, ids []string) (map[string]string, error)
+	Search(ctx context.Context, query string, opts youtube.SearchOptions) ([]string, youtube.Pagination, error)
+	ChannelIDByHandle(ctx context.Context, handle string) (string, error)
+	PlaylistItems(ctx context.Context, playlistID string, pageToken string) ([]string, string, error)
+	Videos(ctx context.Context, ids []string) (map[string]youtube.Video, error)
+	Thumbnail(ctx context.Context, url string) ([]byte, error)
 }
 
 type youtubeLinkExtractorClient interface {
@@ -33,78 +60,325 @@ type Service struct {
 
 	youtubeClient       youtubeClient
 	linkExtractorClient youtubeLinkExtractorClient
+
+	linkCache cacherService
+}
+
+type Option func(*Service)
+
+// WithLinkCache reuses resolved MP3 links for the cache's TTL; converter links expire, so keep it short.
+func WithLinkCache(cache cacherService) Option {
+	return func(s *Service) {
+		s.linkCache = cache
+	}
 }
 
 type VideoInfo struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
+	ID           string `json:"id"`
+	Title        string `json:"title"`
+	ChannelTitle string `json:"channel_title,omitempty"`
+	// DurationSec is zero for live streams and for results cached before it was stored.
+	DurationSec  int       `json:"duration_sec,omitempty"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	PublishedAt  time.Time `json:"published_at"`
+}
+
+// Label is the "mm:ss title" text shown for the video in lists.
+func (v VideoInfo) Label() string {
+	if v.DurationSec <= 0 {
+		return v.Title
+	}
+	return FormatDuration(v.DurationSec) + " " + v.Title
+}
+
+// SearchPage is a page of search results.
+type SearchPage struct {
+	Items []VideoInfo
+	// Pages is estimated from YouTube's total results until the last page has been reached and LastKnown is set.
+	Pages     int
+	LastKnown bool
+	// TotalResults is YouTube's estimate of the results the search has, it can run into millions.
+	TotalResults int
 }
 
-func NewService(youtubeClient youtubeClient, linkExtractorClient youtubeLinkExtractorClient) *Service {
-	return &Service{
-		searchCache:         cacher.NewRedis(cacher.SearchCacheDB, 0),
-		tokenCache:          cacher.NewRedis(cacher.TokenCacheDB, 0),
+// SearchSettings are a user's search preferences, zero values mean the defaults.
+type SearchSettings struct {
+	PageSize int
+	// Order applies to queries without a sort operator.
+	Order string
+}
+
+func (o SearchSettings) pageSize() int {
+	if o.PageSize <= 0 {
+		return DefaultPageSize
+	}
+	return o.PageSize
+}
+
+// cacheKey separates cached pages and page tokens of the same query fetched with different settings.
+func (o SearchSettings) cacheKey() string {
+	return strconv.Itoa(o.pageSize()) + "," + o.Order
+}
+
+// maxPages is the number of pages maxSearchResults fill.
+func (o SearchSettings) maxPages() int {
+	return (maxSearchResults + o.pageSize() - 1) / o.pageSize()
+}
+
+func NewService(
+	searchCache cacherService,
+	tokenCache cacherService,
+	youtubeClient youtubeClient,
+	linkExtractorClient youtubeLinkExtractorClient,
+	options ...Option,
+) *Service {
+	s := &Service{
+		searchCache:         searchCache,
+		tokenCache:          tokenCache,
 		youtubeClient:       youtubeClient,
 		linkExtractorClient: linkExtractorClient,
 	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
 }
 
-func (s *Service) SearchVideos(ctx context.Context, query string, page int, requester string) ([]VideoInfo, int, error) {
+// SearchVideos runs a search for the query, which may contain operators understood by ParseQuery, and returns
+// the page's videos with the number of pages the search has. Results the API cannot filter out are dropped
+// afterwards, so a page may hold fewer items than requested.
+func (s *Service) SearchVideos(ctx context.Context, query string, page int, requester string, settings SearchSettings) (SearchPage, error) {
 	if page < 0 {
-		return nil, 0, errors.New("page must be non-negative")
+		return SearchPage{}, errors.New("page must be non-negative")
 	}
 
-	searchKey := buildCacheKey(requester, query, strconv.Itoa(page))
+	// Page tokens depend on the settings, so they are kept apart from those of other settings.
+	prefix := buildCacheKey(requester, settings.cacheKey())
+	searchKey := buildCacheKey(prefix, query, strconv.Itoa(page))
+	last, lastKnown := s.lastPage(ctx, prefix)
+	if page >= settings.maxPages() || lastKnown && page > last {
+		return SearchPage{}, ErrPageNotFound
+	}
 
-	if cachedValue, ok, err := s.searchCache.Get(ctx, searchKey); err != nil {
-		log.Printf("cache get search key=%s err=%v", searchKey, err)
-	} else if ok {
-		var cachedResult cachedSearchResult
-		if err := json.Unmarshal([]byte(cachedValue), &cachedResult); err == nil {
-			return cachedResult.Items, cachedResult.TotalResults, nil
+	if cachedResult, ok := s.cachedSearch(ctx, searchKey); ok {
+		result := SearchPage{
+			Items:        cachedResult.Items,
+			Pages:        cachedResult.Pages,
+			LastKnown:    lastKnown,
+			TotalResults: cachedResult.TotalResults,
+		}
+		if lastKnown {
+			result.Pages = min(result.Pages, last+1)
 		}
+		return result, nil
 	}
 
-	pageToken := ""
-	if page > 0 {
-		tokenKey := buildCacheKey(requester, strconv.Itoa(page))
-		if cachedToken, ok, err := s.tokenCache.Get(ctx, tokenKey); err != nil {
-			log.Printf("cache get token key=%s err=%v", tokenKey, err)
-		} else if ok {
-			pageToken = cachedToken
-		}
+	filter := ParseQuery(query)
+	if filter.Text == "" && filter.Channel == "" {
+		return SearchPage{}, errors.New("search query is empty")
 	}
+	if filter.Order == "" {
+		filter.Order = settings.Order
+	}
+	s.resolveChannel(ctx, &filter)
 
-	ids, pagination, err := s.youtubeClient.Search(ctx, query, pageToken)
+	pageToken, err := s.pageToken(ctx, filter, prefix, page, settings)
 	if err != nil {
-		return nil, 0, err
+		return SearchPage{}, err
 	}
 
-	titles, err := s.youtubeClient.Videos(ctx, ids)
+	ids, pagination, err := s.youtubeClient.Search(ctx, filter.Text, searchOptions(filter, pageToken, settings))
 	if err != nil {
-		return nil, 0, err
+		return SearchPage{}, err
+	}
+
+	videos, err := s.youtubeClient.Videos(ctx, ids)
+	if err != nil {
+		return SearchPage{}, err
 	}
 
 	items := make([]VideoInfo, 0, len(ids))
 	for _, id := range ids {
-		title, ok := titles[id]
-		if !ok {
+		video, ok := videos[id]
+		if !ok || !filter.Match(video) {
 			continue
 		}
-		items = append(items, VideoInfo{
-			ID:    id,
-			Title: title,
-		})
+		items = append(items, newVideoInfo(video))
+	}
+
+	result := SearchPage{Items: items, Pages: page + 1, LastKnown: lastKnown, TotalResults: pagination.TotalResults}
+	if pagination.NextPageToken != "" {
+		pages := (min(pagination.TotalResults, maxSearchResults) + settings.pageSize() - 1) / settings.pageSize()
+		if lastKnown {
+			pages = min(pages, last+1)
+		}
+		// The estimate may fall short of a next page YouTube has just offered.
+		result.Pages = min(max(pages, page+2), settings.maxPages())
+	} else {
+		s.storeLastPage(ctx, prefix, page)
+		result.LastKnown = true
+	}
+
+	go s.storePageTokens(ctx, prefix, page, pagination.NextPageToken, pagination.PrevPageToken)
+	go s.storeSearch(ctx, searchKey, result)
+
+	return result, nil
+}
+
+// PageWalk returns how many pages SearchVideos has to walk to reach the page, a search each, for charging them
+// up front. Cached pages and pages next to a fetched one need no walk.
+func (s *Service) PageWalk(ctx context.Context, query string, page int, requester string, settings SearchSettings) int {
+	prefix := buildCacheKey(requester, settings.cacheKey())
+	if _, ok := s.cachedSearch(ctx, buildCacheKey(prefix, query, strconv.Itoa(page))); ok {
+		return 0
+	}
+	from, _ := s.nearestPageToken(ctx, prefix, page)
+	return page - from
+}
+
+// pageToken returns the token of the page. A token missing from the cache, as for a jump to a page that is not
+// next to a fetched one, is found by walking the pages from the nearest page with a known token, which costs
+// a search per page walked. ErrPageNotFound is returned when the results end before the page, ErrPageTooFar
+// when the walk would be longer than MaxPageWalk.
+func (s *Service) pageToken(ctx context.Context, filter SearchFilter, prefix string, page int, settings SearchSettings) (string, error) {
+	from, token := s.nearestPageToken(ctx, prefix, page)
+	if page-from > MaxPageWalk {
+		return "", ErrPageTooFar
+	}
+	if from < page {
+		log.Printf("search page walk prefix=%s from=%d to=%d", prefix, from, page)
+	}
+
+	for ; from < page; from++ {
+		_, pagination, err := s.youtubeClient.Search(ctx, filter.Text, searchOptions(filter, token, settings))
+		if err != nil {
+			return "", err
+		}
+		s.storePageTokens(ctx, prefix, from, pagination.NextPageToken, pagination.PrevPageToken)
+		if pagination.NextPageToken == "" {
+			s.storeLastPage(ctx, prefix, from)
+			return "", ErrPageNotFound
+		}
+		token = pagination.NextPageToken
+	}
+	return token, nil
+}
+
+// nearestPageToken finds the closest page up to page with a known token; the first page needs none.
+func (s *Service) nearestPageToken(ctx context.Context, prefix string, page int) (int, string) {
+	for from := page; from > 0; from-- {
+		tokenKey := buildCacheKey(prefix, strconv.Itoa(from))
+		cachedToken, ok, err := s.tokenCache.Get(ctx, tokenKey)
+		if err != nil {
+			log.Printf("cache get token key=%s err=%v", tokenKey, err)
+		} else if ok && cachedToken != "" {
+			return from, cachedToken
+		}
+	}
+	return 0, ""
+}
+
+func searchOptions(filter SearchFilter, pageToken string, settings SearchSettings) youtube.SearchOptions {
+	opts := filter.SearchOptions(pageToken)
+	opts.MaxResults = settings.pageSize()
+	return opts
+}
+
+// resolveChannel looks the channel operator up as a handle; when that fails the filter falls back
+// to matching channel titles.
+func (s *Service) resolveChannel(ctx context.Context, filter *SearchFilter) {
+	handle, ok := filter.channelHandle()
+	if !ok {
+		return
+	}
+	id, err := s.youtubeClient.ChannelIDByHandle(ctx, handle)
+	if err != nil {
+		if !errors.Is(err, youtube.ErrChannelNotFound) {
+			log.Printf("channel lookup handle=%s err=%v", handle, err)
+		}
+		return
 	}
+	filter.ChannelID = id
+}
 
-	go s.storePageTokens(ctx, requester, page, pagination.NextPageToken, pagination.PrevPageToken)
-	go s.storeSearch(ctx, searchKey, items, pagination.TotalResults)
+// Video returns a single video's info, it costs one quota unit and bypasses the search cache.
+func (s *Service) Video(ctx context.Context, id string) (VideoInfo, error) {
+	videos, err := s.youtubeClient.Videos(ctx, []string{id})
+	if err != nil {
+		return VideoInfo{}, err
+	}
+	video, ok := videos[id]
+	if !ok {
+		return VideoInfo{}, errors.New("video not found")
+	}
+	return newVideoInfo(video), nil
+}
 
-	return items, pagination.TotalResults, nil
+// PlaylistTracks lists up to MaxPlaylistTracks videos of a YouTube playlist in order. Private and deleted
+// videos are skipped. Each page of 50 items costs two quota units.
+func (s *Service) PlaylistTracks(ctx context.Context, playlistID string) ([]VideoInfo, error) {
+	tracks := make([]VideoInfo, 0)
+	pageToken := ""
+	for len(tracks) < MaxPlaylistTracks {
+		ids, nextPageToken, err := s.youtubeClient.PlaylistItems(ctx, playlistID, pageToken)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > MaxPlaylistTracks-len(tracks) {
+			ids = ids[:MaxPlaylistTracks-len(tracks)]
+		}
+		if len(ids) > 0 {
+			videos, err := s.youtubeClient.Videos(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			for _, id := range ids {
+				if video, ok := videos[id]; ok {
+					tracks = append(tracks, newVideoInfo(video))
+				}
+			}
+		}
+		if nextPageToken == "" {
+			break
+		}
+		pageToken = nextPageToken
+	}
+	return tracks, nil
+}
+
+func (s *Service) Thumbnail(ctx context.Context, url string) ([]byte, error) {
+	return s.youtubeClient.Thumbnail(ctx, url)
 }
 
 func (s *Service) MP3Link(ctx context.Context, id string) (string, error) {
-	return s.linkExtractorClient.MP3Link(ctx, id)
+	if s.linkCache != nil {
+		if link, ok, err := s.linkCache.Get(ctx, id); err != nil {
+			log.Printf("cache get link id=%s err=%v", id, err)
+		} else if ok && link != "" {
+			return link, nil
+		}
+	}
+
+	link, err := s.linkExtractorClient.MP3Link(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if s.linkCache != nil {
+		if err := s.linkCache.Set(ctx, id, link); err != nil {
+			log.Printf("cache set link id=%s err=%v", id, err)
+		}
+	}
+	return link, nil
+}
+
+// ForgetMP3Link drops the cached link of a video, for links that turned out not to work.
+func (s *Service) ForgetMP3Link(ctx context.Context, id string) {
+	if s.linkCache == nil {
+		return
+	}
+	if err := s.linkCache.Delete(ctx, id); err != nil {
+		log.Printf("cache delete link id=%s err=%v", id, err)
+	}
 }
 
 func (s *Service) ResetSearchState(ctx context.Context, requester string) {
@@ -116,37 +390,82 @@ func (s *Service) ResetSearchState(ctx context.Context, requester string) {
 	}
 }
 
-func (s *Service) storePageTokens(ctx context.Context, requester string, page int, nextToken string, prevToken string) {
+// storePageTokens caches the tokens of the pages next to page under the search's key prefix.
+func (s *Service) storePageTokens(ctx context.Context, prefix string, page int, nextToken string, prevToken string) {
 	if nextToken != "" {
 		if err := s.tokenCache.Set(
 			ctx,
-			buildCacheKey(requester, strconv.Itoa(page+1)),
+			buildCacheKey(prefix, strconv.Itoa(page+1)),
 			nextToken,
 		); err != nil {
-			log.Printf("cache set token next requester=%s err=%v", requester, err)
+			log.Printf("cache set token next prefix=%s err=%v", prefix, err)
 		}
 	}
 
 	if prevToken != "" {
 		if err := s.tokenCache.Set(
 			ctx,
-			buildCacheKey(requester, strconv.Itoa(page-1)),
+			buildCacheKey(prefix, strconv.Itoa(page-1)),
 			prevToken,
 		); err != nil {
-			log.Printf("cache set token prev requester=%s err=%v", requester, err)
+			log.Printf("cache set token prev prefix=%s err=%v", prefix, err)
 		}
 	}
 }
 
+// lastPage reads the last page of the search, known once YouTube has returned a page without a next token.
+func (s *Service) lastPage(ctx context.Context, prefix string) (int, bool) {
+	key := buildCacheKey(prefix, lastPageKey)
+	value, ok, err := s.tokenCache.Get(ctx, key)
+	if err != nil {
+		log.Printf("cache get last page key=%s err=%v", key, err)
+		return 0, false
+	}
+	if !ok || value == "" {
+		return 0, false
+	}
+	last, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return last, true
+}
+
+func (s *Service) storeLastPage(ctx context.Context, prefix string, page int) {
+	if err := s.tokenCache.Set(ctx, buildCacheKey(prefix, lastPageKey), strconv.Itoa(page)); err != nil {
+		log.Printf("cache set last page prefix=%s err=%v", prefix, err)
+	}
+}
+
+// cachedSearchResult is a page with the number of pages, entries without the count are fetched again.
 type cachedSearchResult struct {
 	Items        []VideoInfo `json:"items"`
-	TotalResults int         `json:"total_results"`
+	Pages        int         `json:"pages"`
+	TotalResults int         `json:"total_results,omitempty"`
+}
+
+// cachedSearch reads a cached page, entries that fail to decode or lack the page count are misses.
+func (s *Service) cachedSearch(ctx context.Context, searchKey string) (cachedSearchResult, bool) {
+	cachedValue, ok, err := s.searchCache.Get(ctx, searchKey)
+	if err != nil {
+		log.Printf("cache get search key=%s err=%v", searchKey, err)
+		return cachedSearchResult{}, false
+	}
+	if !ok {
+		return cachedSearchResult{}, false
+	}
+	var cachedResult cachedSearchResult
+	if err := json.Unmarshal([]byte(cachedValue), &cachedResult); err != nil || cachedResult.Pages <= 0 {
+		return cachedSearchResult{}, false
+	}
+	return cachedResult, true
 }
 
-func (s *Service) storeSearch(ctx context.Context, searchKey string, items []VideoInfo, total int) {
+func (s *Service) storeSearch(ctx context.Context, searchKey string, result SearchPage) {
 	cacheValue, err := json.Marshal(cachedSearchResult{
-		Items:        items,
-		TotalResults: total,
+		Items:        result.Items,
+		Pages:        result.Pages,
+		TotalResults: result.TotalResults,
 	})
 	if err != nil {
 		return
@@ -157,6 +476,28 @@ func (s *Service) storeSearch(ctx context.Context, searchKey string, items []Vid
 	}
 }
 
+func newVideoInfo(video youtube.Video) VideoInfo {
+	return VideoInfo{
+		ID:           video.ID,
+		Title:        video.Title,
+		ChannelTitle: video.ChannelTitle,
+		DurationSec:  int(video.Duration.Seconds()),
+		ThumbnailURL: video.ThumbnailURL,
+		PublishedAt:  video.PublishedAt,
+	}
+}
+
+// FormatDuration renders seconds as mm:ss, or hh:mm:ss from an hour up.
+func FormatDuration(totalSec int) string {
+	hours := totalSec / 3600
+	minutes := totalSec % 3600 / 60
+	seconds := totalSec % 60
+	if hours > 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+}
+
 func buildCacheKey(parts ...string) string {
 	return strings.Join(parts, "#")
 }
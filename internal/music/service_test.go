@@ -0,0 +1,217 @@
+package music
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/youtube"
+)
+
+// fakeYouTube serves pages of one video each; page tokens are "p<page>".
+type fakeYouTube struct {
+	pages        int
+	totalResults int
+
+	mu     sync.Mutex
+	tokens []string
+}
+
+func (f *fakeYouTube) Search(_ context.Context, _ string, opts youtube.SearchOptions) ([]string, youtube.Pagination, error) {
+	f.mu.Lock()
+	f.tokens = append(f.tokens, opts.PageToken)
+	f.mu.Unlock()
+
+	page := 0
+	if opts.PageToken != "" {
+		page, _ = strconv.Atoi(strings.TrimPrefix(opts.PageToken, "p"))
+	}
+	pagination := youtube.Pagination{TotalResults: f.totalResults}
+	if page+1 < f.pages {
+		pagination.NextPageToken = "p" + strconv.Itoa(page+1)
+	}
+	if page > 0 {
+		pagination.PrevPageToken = "p" + strconv.Itoa(page-1)
+	}
+	return []string{"v" + strconv.Itoa(page)}, pagination, nil
+}
+
+func (f *fakeYouTube) searchedTokens() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.tokens...)
+}
+
+func (f *fakeYouTube) ChannelIDByHandle(context.Context, string) (string, error) {
+	return "", youtube.ErrChannelNotFound
+}
+
+func (f *fakeYouTube) PlaylistItems(context.Context, string, string) ([]string, string, error) {
+	return nil, "", nil
+}
+
+func (f *fakeYouTube) Videos(_ context.Context, ids []string) (map[string]youtube.Video, error) {
+	videos := make(map[string]youtube.Video, len(ids))
+	for _, id := range ids {
+		videos[id] = youtube.Video{ID: id, Title: id}
+	}
+	return videos, nil
+}
+
+func (f *fakeYouTube) Thumbnail(context.Context, string) ([]byte, error) {
+	return nil, nil
+}
+
+func testService(client *fakeYouTube) *Service {
+	return NewService(
+		cacher.NewMemory(cacher.SearchCacheDB, 0, 0),
+		cacher.NewMemory(cacher.TokenCacheDB, 0, 0),
+		client,
+		nil,
+	)
+}
+
+func TestSearchJumpWalksPageTokens(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeYouTube{pages: 10, totalResults: 100}
+	s := testService(client)
+
+	if walk := s.PageWalk(ctx, "song", 4, "user", SearchSettings{}); walk != 4 {
+		t.Fatalf("walk to page 4 = %d pages, want 4", walk)
+	}
+	result, err := s.SearchVideos(ctx, "song", 4, "user", SearchSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Items) != 1 || result.Items[0].ID != "v4" || result.Pages != 10 || result.LastKnown {
+		t.Fatalf("page 4 = %+v", result)
+	}
+	if got, want := client.searchedTokens(), []string{"", "p1", "p2", "p3", "p4"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("searched tokens %v, want %v", got, want)
+	}
+
+	// Tokens found on the walk are cached, so an earlier page is fetched directly.
+	if walk := s.PageWalk(ctx, "song", 2, "user", SearchSettings{}); walk != 0 {
+		t.Fatalf("walk to page 2 = %d pages, want none", walk)
+	}
+	if result, err := s.SearchVideos(ctx, "song", 2, "user", SearchSettings{}); err != nil || result.Items[0].ID != "v2" {
+		t.Fatalf("page 2 = %+v, %v", result, err)
+	}
+	if got := client.searchedTokens(); got[len(got)-1] != "p2" || len(got) != 6 {
+		t.Fatalf("searched tokens %v, want one more search for p2", got)
+	}
+}
+
+func TestSearchJumpTooFar(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeYouTube{pages: 20, totalResults: 200}
+	s := testService(client)
+
+	if _, err := s.SearchVideos(ctx, "song", MaxPageWalk+1, "user", SearchSettings{}); !errors.Is(err, ErrPageTooFar) {
+		t.Fatalf("jump past the walk limit err = %v", err)
+	}
+	if got := client.searchedTokens(); len(got) != 0 {
+		t.Fatalf("a refused jump searched %v", got)
+	}
+}
+
+func TestSearchPagesCappedByResults(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeYouTube{pages: 100, totalResults: 1000000}
+	s := testService(client)
+
+	result, err := s.SearchVideos(ctx, "song", 0, "user", SearchSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pages != maxSearchResults/DefaultPageSize || result.LastKnown {
+		t.Fatalf("pages = %d, last known %t, want an estimate of %d", result.Pages, result.LastKnown, maxSearchResults/DefaultPageSize)
+	}
+	if result.TotalResults != 1000000 {
+		t.Fatalf("total results = %d, want YouTube's uncapped estimate", result.TotalResults)
+	}
+	s.storeSearch(ctx, "key", result)
+	if cached, ok := s.cachedSearch(ctx, "key"); !ok || cached.TotalResults != 1000000 {
+		t.Fatalf("cached total results = %d, %t", cached.TotalResults, ok)
+	}
+
+	if _, err := s.SearchVideos(ctx, "song", result.Pages, "user", SearchSettings{}); !errors.Is(err, ErrPageNotFound) {
+		t.Fatalf("page past the cap err = %v", err)
+	}
+	if result, _ := s.SearchVideos(ctx, "other", 0, "user", SearchSettings{PageSize: 20}); result.Pages != maxSearchResults/20 {
+		t.Fatalf("pages of 20 = %d", result.Pages)
+	}
+}
+
+func TestSearchPagesCutAtLastPage(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeYouTube{pages: 3, totalResults: 150}
+	s := testService(client)
+
+	if _, err := s.SearchVideos(ctx, "song", 4, "user", SearchSettings{}); !errors.Is(err, ErrPageNotFound) {
+		t.Fatalf("page past the results err = %v", err)
+	}
+	searches := len(client.searchedTokens())
+	if searches != 3 {
+		t.Fatalf("walk made %d searches, want 3", searches)
+	}
+
+	result, err := s.SearchVideos(ctx, "song", 1, "user", SearchSettings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Pages != 3 || !result.LastKnown {
+		t.Fatalf("pages = %d, last known %t, want the 3 YouTube returned", result.Pages, result.LastKnown)
+	}
+	if _, err := s.SearchVideos(ctx, "song", 5, "user", SearchSettings{}); !errors.Is(err, ErrPageNotFound) {
+		t.Fatalf("page past the last one err = %v", err)
+	}
+	if got := len(client.searchedTokens()); got != searches+1 {
+		t.Fatalf("made %d searches, a known last page must not be walked again", got-searches)
+	}
+}
+
+// fakeExtractor hands out a new link on every call.
+type fakeExtractor struct {
+	calls int
+}
+
+func (f *fakeExtractor) MP3Link(_ context.Context, id string) (string, error) {
+	f.calls++
+	return "https://cdn.example/" + id + "/" + strconv.Itoa(f.calls) + ".mp3", nil
+}
+
+func TestMP3LinkCache(t *testing.T) {
+	ctx := context.Background()
+	extractor := &fakeExtractor{}
+	s := NewService(
+		cacher.NewMemory(cacher.SearchCacheDB, 0, 0),
+		cacher.NewMemory(cacher.TokenCacheDB, 0, 0),
+		&fakeYouTube{},
+		extractor,
+		WithLinkCache(cacher.NewMemory(cacher.LinkCacheDB, 0, 0)),
+	)
+
+	first, err := s.MP3Link(ctx, "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := s.MP3Link(ctx, "dQw4w9WgXcQ"); again != first || extractor.calls != 1 {
+		t.Fatalf("cached link = %q after %d extractions, want %q from one", again, extractor.calls, first)
+	}
+
+	// Forgetting a link keeps the links of other videos.
+	other, _ := s.MP3Link(ctx, "dQw4w9WgXcR")
+	s.ForgetMP3Link(ctx, "dQw4w9WgXcQ")
+	if fresh, _ := s.MP3Link(ctx, "dQw4w9WgXcQ"); fresh == first {
+		t.Fatalf("forgotten link %q was served again", fresh)
+	}
+	if kept, _ := s.MP3Link(ctx, "dQw4w9WgXcR"); kept != other {
+		t.Fatalf("link of another video = %q, want %q", kept, other)
+	}
+}
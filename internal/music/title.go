@@ -0,0 +1,52 @@
+package music
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// titleNoiseRE matches bracketed video decorations such as "(Official Video)" or "[Lyrics]".
+	titleNoiseRE = regexp.MustCompile(`(?i)\s*[\(\[【][^\)\]】]*\b(official|lyrics?|audio|video|videoclip|clip|visuali[sz]er|hd|hq|4k|mv|m/v)\b[^\)\]】]*[\)\]】]`)
+	// titleSuffixRE matches trailing decorations after a separator, e.g. "| Official Music Video".
+	titleSuffixRE = regexp.MustCompile(`(?i)\s*[|｜/]\s*(official|lyrics?|audio|video|visuali[sz]er)\b.*$`)
+	// channelSuffixRE matches the parts auto-generated and label channels append to artist names.
+	channelSuffixRE = regexp.MustCompile(`(?i)(\s*-\s*topic|vevo|\s*official)$`)
+
+	titleSeparators = []string{" - ", " – ", " — ", " -- ", " ~ "}
+)
+
+// SplitTitle derives performer and track title from a YouTube video title, falling back to the channel name
+// as the performer when the title has no "Artist - Song" separator.
+func SplitTitle(videoTitle string, channelTitle string) (string, string) {
+	title := titleNoiseRE.ReplaceAllString(videoTitle, "")
+	title = titleSuffixRE.ReplaceAllString(title, "")
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		title = strings.TrimSpace(videoTitle)
+	}
+
+	for _, separator := range titleSeparators {
+		performer, song, ok := strings.Cut(title, separator)
+		if !ok {
+			continue
+		}
+		performer = strings.TrimSpace(performer)
+		song = trimQuotes(strings.TrimSpace(song))
+		if performer != "" && song != "" {
+			return performer, song
+		}
+	}
+
+	performer := strings.TrimSpace(channelSuffixRE.ReplaceAllString(strings.TrimSpace(channelTitle), ""))
+	return performer, trimQuotes(title)
+}
+
+func trimQuotes(s string) string {
+	for _, pair := range [][2]string{{`"`, `"`}, {"«", "»"}, {"“", "”"}, {"'", "'"}} {
+		if len(s) > len(pair[0])+len(pair[1]) && strings.HasPrefix(s, pair[0]) && strings.HasSuffix(s, pair[1]) {
+			return strings.TrimSpace(s[len(pair[0]) : len(s)-len(pair[1])])
+		}
+	}
+	return s
+}
@@ -0,0 +1,27 @@
+package music
+
+import "testing"
+
+func TestSplitTitle(t *testing.T) {
+	tests := []struct {
+		videoTitle    string
+		channelTitle  string
+		wantPerformer string
+		wantTitle     string
+	}{
+		{"Rick Astley - Never Gonna Give You Up (Official Music Video)", "Rick Astley", "Rick Astley", "Never Gonna Give You Up"},
+		{"Daft Punk – Get Lucky [Official Audio] ft. Pharrell Williams", "Daft Punk", "Daft Punk", "Get Lucky ft. Pharrell Williams"},
+		{"Queen - Bohemian Rhapsody (Remastered 2011)", "Queen Official", "Queen", "Bohemian Rhapsody (Remastered 2011)"},
+		{"Bad Guy | Official Music Video", "BillieEilishVEVO", "BillieEilish", "Bad Guy"},
+		{"Numb", "Linkin Park - Topic", "Linkin Park", "Numb"},
+		{`Adele - "Hello"`, "AdeleVEVO", "Adele", "Hello"},
+	}
+
+	for _, tt := range tests {
+		performer, title := SplitTitle(tt.videoTitle, tt.channelTitle)
+		if performer != tt.wantPerformer || title != tt.wantTitle {
+			t.Errorf("SplitTitle(%q, %q) = %q, %q; want %q, %q",
+				tt.videoTitle, tt.channelTitle, performer, title, tt.wantPerformer, tt.wantTitle)
+		}
+	}
+}
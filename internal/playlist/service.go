@@ -0,0 +1,248 @@
+package playlist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxPlaylists = 20
+	MaxTracks    = 200
+	MaxNameRunes = 64
+)
+
+var (
+	ErrNotFound      = errors.New("playlist not found")
+	ErrTrackNotFound = errors.New("track not found")
+	ErrTrackExists   = errors.New("track already in playlist")
+	ErrInvalidName   = errors.New("invalid playlist name")
+	ErrNameTaken     = errors.New("playlist name already taken")
+	ErrLimitReached  = errors.New("playlist limit reached")
+)
+
+type cacherService interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+type Track struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type Playlist struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Tracks    []Track   `json:"tracks"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Service stores playlists per owner as a single JSON document.
+type Service struct {
+	cache cacherService
+	// mu serializes read-modify-write cycles on owner documents.
+	mu sync.Mutex
+}
+
+type ownerPlaylists struct {
+	NextID    int        `json:"next_id"`
+	Playlists []Playlist `json:"playlists"`
+}
+
+func NewService(cache cacherService) *Service {
+	return &Service{cache: cache}
+}
+
+func (s *Service) List(ctx context.Context, owner string) ([]Playlist, error) {
+	doc, err := s.load(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Playlists, nil
+}
+
+func (s *Service) Get(ctx context.Context, owner string, id int) (Playlist, error) {
+	doc, err := s.load(ctx, owner)
+	if err != nil {
+		return Playlist{}, err
+	}
+	idx := doc.index(id)
+	if idx < 0 {
+		return Playlist{}, ErrNotFound
+	}
+	return doc.Playlists[idx], nil
+}
+
+func (s *Service) Create(ctx context.Context, owner string, name string) (Playlist, error) {
+	name, err := normalizeName(name)
+	if err != nil {
+		return Playlist{}, err
+	}
+
+	var created Playlist
+	err = s.update(ctx, owner, func(doc *ownerPlaylists) error {
+		if len(doc.Playlists) >= MaxPlaylists {
+			return ErrLimitReached
+		}
+		if doc.nameTaken(name, 0) {
+			return ErrNameTaken
+		}
+		doc.NextID++
+		created = Playlist{
+			ID:        doc.NextID,
+			Name:      name,
+			CreatedAt: time.Now().UTC(),
+		}
+		doc.Playlists = append(doc.Playlists, created)
+		return nil
+	})
+	return created, err
+}
+
+func (s *Service) Rename(ctx context.Context, owner string, id int, name string) error {
+	name, err := normalizeName(name)
+	if err != nil {
+		return err
+	}
+
+	return s.update(ctx, owner, func(doc *ownerPlaylists) error {
+		idx := doc.index(id)
+		if idx < 0 {
+			return ErrNotFound
+		}
+		if doc.nameTaken(name, id) {
+			return ErrNameTaken
+		}
+		doc.Playlists[idx].Name = name
+		return nil
+	})
+}
+
+func (s *Service) Delete(ctx context.Context, owner string, id int) error {
+	return s.update(ctx, owner, func(doc *ownerPlaylists) error {
+		idx := doc.index(id)
+		if idx < 0 {
+			return ErrNotFound
+		}
+		doc.Playlists = append(doc.Playlists[:idx], doc.Playlists[idx+1:]...)
+		return nil
+	})
+}
+
+func (s *Service) AddTrack(ctx context.Context, owner string, id int, track Track) (Playlist, error) {
+	if strings.TrimSpace(track.ID) == "" {
+		return Playlist{}, errors.New("track id is empty")
+	}
+
+	var updated Playlist
+	err := s.update(ctx, owner, func(doc *ownerPlaylists) error {
+		idx := doc.index(id)
+		if idx < 0 {
+			return ErrNotFound
+		}
+		p := &doc.Playlists[idx]
+		for _, existing := range p.Tracks {
+			if existing.ID == track.ID {
+				return ErrTrackExists
+			}
+		}
+		if len(p.Tracks) >= MaxTracks {
+			return ErrLimitReached
+		}
+		p.Tracks = append(p.Tracks, track)
+		updated = *p
+		return nil
+	})
+	return updated, err
+}
+
+func (s *Service) RemoveTrack(ctx context.Context, owner string, id int, trackID string) (Playlist, error) {
+	var updated Playlist
+	err := s.update(ctx, owner, func(doc *ownerPlaylists) error {
+		idx := doc.index(id)
+		if idx < 0 {
+			return ErrNotFound
+		}
+		p := &doc.Playlists[idx]
+		for i, existing := range p.Tracks {
+			if existing.ID == trackID {
+				p.Tracks = append(p.Tracks[:i], p.Tracks[i+1:]...)
+				updated = *p
+				return nil
+			}
+		}
+		return ErrTrackNotFound
+	})
+	return updated, err
+}
+
+func (s *Service) update(ctx context.Context, owner string, fn func(doc *ownerPlaylists) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc, err := s.load(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if err := fn(&doc); err != nil {
+		return err
+	}
+
+	value, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(ctx, owner, string(value))
+}
+
+func (s *Service) load(ctx context.Context, owner string) (ownerPlaylists, error) {
+	if owner == "" {
+		return ownerPlaylists{}, errors.New("owner is empty")
+	}
+
+	value, ok, err := s.cache.Get(ctx, owner)
+	if err != nil {
+		return ownerPlaylists{}, err
+	}
+	if !ok || value == "" {
+		return ownerPlaylists{}, nil
+	}
+
+	var doc ownerPlaylists
+	if err := json.Unmarshal([]byte(value), &doc); err != nil {
+		return ownerPlaylists{}, err
+	}
+	return doc, nil
+}
+
+func (d *ownerPlaylists) index(id int) int {
+	for i, p := range d.Playlists {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *ownerPlaylists) nameTaken(name string, exceptID int) bool {
+	for _, p := range d.Playlists {
+		if p.ID != exceptID && strings.EqualFold(p.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || utf8.RuneCountInString(name) > MaxNameRunes {
+		return "", ErrInvalidName
+	}
+	return name, nil
+}
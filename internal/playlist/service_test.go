@@ -0,0 +1,139 @@
+package playlist
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"music-bot-v2/internal/cacher"
+)
+
+func TestCreate(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(cacher.NewMemory(cacher.PlaylistCacheDB, 0, 0))
+
+	p, err := s.Create(ctx, "owner", "  Road   trip ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ID != 1 || p.Name != "Road trip" {
+		t.Fatalf("created %+v, want ID 1 and a normalized name", p)
+	}
+
+	tests := []struct {
+		name string
+		want error
+	}{
+		{"road TRIP", ErrNameTaken},
+		{"   ", ErrInvalidName},
+		{strings.Repeat("я", MaxNameRunes+1), ErrInvalidName},
+	}
+	for _, tt := range tests {
+		if _, err := s.Create(ctx, "owner", tt.name); !errors.Is(err, tt.want) {
+			t.Errorf("Create(%q) err = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// Names are unique per owner only.
+	if _, err := s.Create(ctx, "other", "Road trip"); err != nil {
+		t.Fatalf("another owner's playlist err = %v", err)
+	}
+}
+
+func TestCreateLimit(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(cacher.NewMemory(cacher.PlaylistCacheDB, 0, 0))
+
+	for i := 0; i < MaxPlaylists; i++ {
+		if _, err := s.Create(ctx, "owner", "list "+strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.Create(ctx, "owner", "one more"); !errors.Is(err, ErrLimitReached) {
+		t.Fatalf("playlist past the limit err = %v", err)
+	}
+}
+
+func TestTracks(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(cacher.NewMemory(cacher.PlaylistCacheDB, 0, 0))
+	p, _ := s.Create(ctx, "owner", "mix")
+
+	for _, id := range []string{"a", "b", "c"} {
+		if _, err := s.AddTrack(ctx, "owner", p.ID, Track{ID: id, Title: "Song " + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.AddTrack(ctx, "owner", p.ID, Track{ID: "b"}); !errors.Is(err, ErrTrackExists) {
+		t.Fatalf("duplicate track err = %v", err)
+	}
+	if _, err := s.AddTrack(ctx, "other", p.ID, Track{ID: "d"}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("adding to another owner's playlist err = %v", err)
+	}
+
+	updated, err := s.RemoveTrack(ctx, "owner", p.ID, "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updated.Tracks) != 2 || updated.Tracks[0].ID != "a" || updated.Tracks[1].ID != "c" {
+		t.Fatalf("tracks after removal = %+v", updated.Tracks)
+	}
+	if _, err := s.RemoveTrack(ctx, "owner", p.ID, "b"); !errors.Is(err, ErrTrackNotFound) {
+		t.Fatalf("removing a missing track err = %v", err)
+	}
+}
+
+func TestTrackLimit(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(cacher.NewMemory(cacher.PlaylistCacheDB, 0, 0))
+	p, _ := s.Create(ctx, "owner", "long")
+
+	for i := 0; i < MaxTracks; i++ {
+		if _, err := s.AddTrack(ctx, "owner", p.ID, Track{ID: strconv.Itoa(i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.AddTrack(ctx, "owner", p.ID, Track{ID: "over"}); !errors.Is(err, ErrLimitReached) {
+		t.Fatalf("track past the limit err = %v", err)
+	}
+}
+
+func TestRenameAndDelete(t *testing.T) {
+	ctx := context.Background()
+	s := NewService(cacher.NewMemory(cacher.PlaylistCacheDB, 0, 0))
+	first, _ := s.Create(ctx, "owner", "first")
+	second, _ := s.Create(ctx, "owner", "second")
+
+	if err := s.Rename(ctx, "owner", first.ID, "Second"); !errors.Is(err, ErrNameTaken) {
+		t.Fatalf("rename to a taken name err = %v", err)
+	}
+	// Changing only the case of its own name is allowed.
+	if err := s.Rename(ctx, "owner", second.ID, "SECOND"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Rename(ctx, "owner", 42, "missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("rename of a missing playlist err = %v", err)
+	}
+
+	if err := s.Delete(ctx, "owner", first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete(ctx, "owner", first.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("deleting twice err = %v", err)
+	}
+	list, err := s.List(ctx, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].Name != "SECOND" {
+		t.Fatalf("playlists = %+v", list)
+	}
+
+	// IDs are not reused after a deletion, callback data may still point at the deleted playlist.
+	third, _ := s.Create(ctx, "owner", "third")
+	if third.ID != 3 {
+		t.Fatalf("new playlist ID = %d, want 3", third.ID)
+	}
+}
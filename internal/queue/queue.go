@@ -0,0 +1,176 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+var ErrFull = errors.New("queue is full")
+
+var jobsGauge = metrics.NewGaugeVec(
+	"queue_jobs",
+	"Jobs in the delivery queue by state (waiting, running).",
+	"state",
+)
+
+// Config describes the worker pool.
+type Config struct {
+	Workers  int `env:"WORKERS" envDefault:"4"`
+	Capacity int `env:"CAPACITY" envDefault:"100"`
+}
+
+// Job is a unit of work identified by Key, which Cancel refers to.
+type Job struct {
+	Key string
+	Run func(ctx context.Context)
+	// Moved, if set, is called with the job's new 1-based position while it waits for a worker.
+	Moved func(position int)
+}
+
+// Queue runs jobs in FIFO order on a fixed number of workers. Each running job has its own context,
+// derived from the queue's, that Cancel aborts.
+type Queue struct {
+	ctx      context.Context
+	capacity int
+	signal   chan struct{}
+
+	mu      sync.Mutex
+	waiting []Job
+	running map[string]context.CancelFunc
+}
+
+// New starts the workers; they stop when ctx is done.
+func New(ctx context.Context, cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = 1
+	}
+	q := &Queue{
+		ctx:      ctx,
+		capacity: cfg.Capacity,
+		signal:   make(chan struct{}, cfg.Capacity),
+		running:  make(map[string]context.CancelFunc),
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// Submit enqueues the job and returns its 1-based position among waiting jobs.
+func (q *Queue) Submit(job Job) (int, error) {
+	q.mu.Lock()
+	if len(q.waiting) >= q.capacity {
+		q.mu.Unlock()
+		return 0, ErrFull
+	}
+	q.waiting = append(q.waiting, job)
+	position := len(q.waiting)
+	jobsGauge.Set(float64(len(q.waiting)), "waiting")
+	q.mu.Unlock()
+
+	q.signal <- struct{}{}
+	return position, nil
+}
+
+// Cancel removes a waiting job or aborts the context of a running one, reporting whether the key was found.
+func (q *Queue) Cancel(key string) bool {
+	q.mu.Lock()
+	if cancel, ok := q.running[key]; ok {
+		q.mu.Unlock()
+		cancel()
+		return true
+	}
+	for i, job := range q.waiting {
+		if job.Key != key {
+			continue
+		}
+		q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+		// Drop the job's signal so signals never outnumber waiting jobs and Submit cannot block on a full channel.
+		select {
+		case <-q.signal:
+		default:
+		}
+		moved := q.movedLocked(i)
+		jobsGauge.Set(float64(len(q.waiting)), "waiting")
+		q.mu.Unlock()
+		go notify(moved)
+		return true
+	}
+	q.mu.Unlock()
+	return false
+}
+
+func (q *Queue) work() {
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-q.signal:
+		}
+
+		job, ok := q.next()
+		if !ok {
+			// The job behind this signal was cancelled while waiting.
+			continue
+		}
+		q.run(job)
+	}
+}
+
+func (q *Queue) next() (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.waiting) == 0 {
+		return Job{}, false
+	}
+	job := q.waiting[0]
+	q.waiting = q.waiting[1:]
+	jobsGauge.Set(float64(len(q.waiting)), "waiting")
+	go notify(q.movedLocked(0))
+	return job, true
+}
+
+func (q *Queue) run(job Job) {
+	ctx, cancel := context.WithCancel(q.ctx)
+	q.mu.Lock()
+	q.running[job.Key] = cancel
+	jobsGauge.Set(float64(len(q.running)), "running")
+	q.mu.Unlock()
+
+	defer func() {
+		cancel()
+		q.mu.Lock()
+		delete(q.running, job.Key)
+		jobsGauge.Set(float64(len(q.running)), "running")
+		q.mu.Unlock()
+	}()
+	job.Run(ctx)
+}
+
+type moved struct {
+	fn       func(position int)
+	position int
+}
+
+// movedLocked collects the position updates of the jobs from index on, which have just moved up by one.
+func (q *Queue) movedLocked(from int) []moved {
+	var updates []moved
+	for i := from; i < len(q.waiting); i++ {
+		if q.waiting[i].Moved != nil {
+			updates = append(updates, moved{fn: q.waiting[i].Moved, position: i + 1})
+		}
+	}
+	return updates
+}
+
+func notify(updates []moved) {
+	for _, update := range updates {
+		update.fn(update.position)
+	}
+}
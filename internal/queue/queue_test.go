@@ -0,0 +1,105 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestQueueRunsJobsInOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := New(ctx, Config{Workers: 1, Capacity: 10})
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	release := make(chan struct{})
+	for _, key := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		key := key
+		_, err := q.Submit(Job{Key: key, Run: func(context.Context) {
+			defer wg.Done()
+			if key == "a" {
+				<-release
+			}
+			mu.Lock()
+			order = append(order, key)
+			mu.Unlock()
+		}})
+		if err != nil {
+			t.Fatalf("Submit error: %v", err)
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if len(order) != 3 || order[0] != "a" || order[1] != "b" || order[2] != "c" {
+		t.Fatalf("unexpected order %v", order)
+	}
+}
+
+func TestQueuePositionsAndCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := New(ctx, Config{Workers: 1, Capacity: 2})
+
+	started := make(chan struct{})
+	stopped := make(chan error, 1)
+	if _, err := q.Submit(Job{Key: "running", Run: func(ctx context.Context) {
+		close(started)
+		<-ctx.Done()
+		stopped <- ctx.Err()
+	}}); err != nil {
+		t.Fatalf("Submit error: %v", err)
+	}
+	<-started
+
+	positions := make(chan int, 4)
+	ran := make(chan string, 2)
+	for _, key := range []string{"first", "second"} {
+		key := key
+		job := Job{Key: key, Run: func(context.Context) { ran <- key }}
+		if key == "second" {
+			job.Moved = func(position int) { positions <- position }
+		}
+		position, err := q.Submit(job)
+		if err != nil {
+			t.Fatalf("Submit error: %v", err)
+		}
+		if key == "second" && position != 2 {
+			t.Fatalf("expected position 2, got %d", position)
+		}
+	}
+	if _, err := q.Submit(Job{Key: "overflow", Run: func(context.Context) {}}); !errors.Is(err, ErrFull) {
+		t.Fatalf("expected ErrFull, got %v", err)
+	}
+
+	if !q.Cancel("first") {
+		t.Fatal("waiting job not cancelled")
+	}
+	if position := <-positions; position != 1 {
+		t.Fatalf("expected to move to position 1, got %d", position)
+	}
+
+	if !q.Cancel("running") {
+		t.Fatal("running job not cancelled")
+	}
+	if err := <-stopped; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled context, got %v", err)
+	}
+
+	select {
+	case key := <-ran:
+		if key != "second" {
+			t.Fatalf("cancelled job %q ran", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("remaining job did not run")
+	}
+	if q.Cancel("missing") {
+		t.Fatal("unknown key reported as cancelled")
+	}
+}
@@ -0,0 +1,13 @@
+package ratelimit
+
+import "time"
+
+// Config describes the per-user buckets; a bucket with zero burst is not limited.
+type Config struct {
+	SearchBurst      int           `env:"SEARCH_BURST" envDefault:"5"`
+	SearchInterval   time.Duration `env:"SEARCH_INTERVAL" envDefault:"12s"`
+	PageBurst        int           `env:"PAGE_BURST" envDefault:"20"`
+	PageInterval     time.Duration `env:"PAGE_INTERVAL" envDefault:"2s"`
+	DownloadBurst    int           `env:"DOWNLOAD_BURST" envDefault:"10"`
+	DownloadInterval time.Duration `env:"DOWNLOAD_INTERVAL" envDefault:"10s"`
+}
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+// Kind selects the bucket an action draws tokens from.
+type Kind string
+
+const (
+	Search   Kind = "search"
+	Page     Kind = "page"
+	Download Kind = "download"
+)
+
+var rejectionsTotal = metrics.NewCounterVec(
+	"rate_limit_rejections_total",
+	"Actions rejected by the per-user rate limiter by bucket.",
+	"bucket",
+)
+
+// Bucket is a token bucket holding up to Burst tokens and regaining one every Interval.
+type Bucket struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// Store keeps bucket state; Take spends n tokens if there are as many, or reports how long until there are. A
+// refused Take spends nothing.
+type Store interface {
+	Take(ctx context.Context, key string, bucket Bucket, n int, now time.Time) (bool, time.Duration, error)
+	Ping(ctx context.Context) error
+}
+
+// Limiter applies per-user token buckets for each kind of action.
+type Limiter struct {
+	store   Store
+	buckets map[Kind]Bucket
+	exempt  map[string]bool
+	now     func() time.Time
+}
+
+// New creates a limiter; requesters listed in exempt, such as admins, are never limited.
+func New(store Store, cfg Config, exempt []string) *Limiter {
+	limiter := &Limiter{
+		store: store,
+		buckets: map[Kind]Bucket{
+			Search:   {Burst: cfg.SearchBurst, Interval: cfg.SearchInterval},
+			Page:     {Burst: cfg.PageBurst, Interval: cfg.PageInterval},
+			Download: {Burst: cfg.DownloadBurst, Interval: cfg.DownloadInterval},
+		},
+		exempt: make(map[string]bool, len(exempt)),
+		now:    time.Now,
+	}
+	for _, requester := range exempt {
+		limiter.exempt[requester] = true
+	}
+	return limiter
+}
+
+// Allow spends a token of the requester's bucket, returning how long to wait when it is empty.
+// The limiter fails open: when the store is unreachable the action is allowed.
+func (l *Limiter) Allow(ctx context.Context, kind Kind, requester string) (bool, time.Duration) {
+	return l.AllowN(ctx, kind, requester, 1)
+}
+
+// AllowN spends n tokens of the requester's bucket at once, for an action that counts as several, and spends
+// none when the bucket holds fewer. An action costing more than the burst takes the whole bucket.
+func (l *Limiter) AllowN(ctx context.Context, kind Kind, requester string, n int) (bool, time.Duration) {
+	if l == nil || l.exempt[requester] || n <= 0 {
+		return true, 0
+	}
+	bucket, ok := l.buckets[kind]
+	if !ok || bucket.Burst <= 0 || bucket.Interval <= 0 {
+		return true, 0
+	}
+
+	allowed, retryAfter, err := l.store.Take(ctx, string(kind)+"#"+requester, bucket, min(n, bucket.Burst), l.now())
+	if err != nil {
+		log.Printf("rate limit take bucket=%s requester=%s err=%v", kind, requester, err)
+		return true, 0
+	}
+	if !allowed {
+		rejectionsTotal.Inc(string(kind))
+	}
+	return allowed, retryAfter
+}
+
+// Ping checks the bucket store.
+func (l *Limiter) Ping(ctx context.Context) error {
+	return l.store.Ping(ctx)
+}
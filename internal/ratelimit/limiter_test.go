@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func testLimiter(store Store, now *time.Time) *Limiter {
+	limiter := New(store, Config{
+		SearchBurst:    2,
+		SearchInterval: 10 * time.Second,
+	}, []string{"admin"})
+	limiter.now = func() time.Time { return *now }
+	return limiter
+}
+
+func testStores(t *testing.T) map[string]Store {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return map[string]Store{
+		"memory": NewMemory(),
+		"redis":  NewRedis(client),
+	}
+}
+
+func TestLimiterBucket(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Unix(1000, 0)
+			limiter := testLimiter(store, &now)
+
+			for i := 0; i < 2; i++ {
+				if ok, _ := limiter.Allow(ctx, Search, "user"); !ok {
+					t.Fatalf("request %d rejected within burst", i+1)
+				}
+			}
+			ok, retryAfter := limiter.Allow(ctx, Search, "user")
+			if ok || retryAfter != 10*time.Second {
+				t.Fatalf("expected rejection with 10s wait, got ok=%v wait=%v", ok, retryAfter)
+			}
+
+			now = now.Add(4 * time.Second)
+			if ok, retryAfter := limiter.Allow(ctx, Search, "user"); ok || retryAfter != 6*time.Second {
+				t.Fatalf("expected rejection with 6s wait, got ok=%v wait=%v", ok, retryAfter)
+			}
+
+			now = now.Add(6 * time.Second)
+			if ok, _ := limiter.Allow(ctx, Search, "user"); !ok {
+				t.Fatal("expected a refilled token")
+			}
+			if ok, _ := limiter.Allow(ctx, Search, "other"); !ok {
+				t.Fatal("buckets must be per requester")
+			}
+		})
+	}
+}
+
+func TestLimiterAllowN(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			now := time.Unix(1000, 0)
+			limiter := New(store, Config{PageBurst: 4, PageInterval: 10 * time.Second}, nil)
+			limiter.now = func() time.Time { return now }
+
+			if ok, _ := limiter.AllowN(ctx, Page, "user", 3); !ok {
+				t.Fatal("walk within the burst rejected")
+			}
+			// One token is left, a walk of three is refused without spending it.
+			ok, retryAfter := limiter.AllowN(ctx, Page, "user", 3)
+			if ok || retryAfter != 20*time.Second {
+				t.Fatalf("expected rejection with 20s wait, got ok=%v wait=%v", ok, retryAfter)
+			}
+			if ok, _ := limiter.Allow(ctx, Page, "user"); !ok {
+				t.Fatal("refused walk spent the remaining token")
+			}
+
+			// A walk longer than the burst waits for the whole bucket instead of never passing.
+			now = now.Add(40 * time.Second)
+			if ok, _ := limiter.AllowN(ctx, Page, "user", 6); !ok {
+				t.Fatal("walk longer than the burst rejected with a full bucket")
+			}
+		})
+	}
+}
+
+func TestLimiterExemptAndUnlimited(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	limiter := testLimiter(NewMemory(), &now)
+
+	for i := 0; i < 10; i++ {
+		if ok, _ := limiter.Allow(ctx, Search, "admin"); !ok {
+			t.Fatal("exempt requester was limited")
+		}
+		if ok, _ := limiter.Allow(ctx, Download, "user"); !ok {
+			t.Fatal("bucket with zero burst was limited")
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Memory keeps buckets in the process, for the memory cache backend; limits are not shared between replicas.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	lastEvict time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket will have refilled, after which it behaves like a missing one.
+	full time.Time
+}
+
+const evictInterval = time.Minute
+
+func NewMemory() *Memory {
+	return &Memory{buckets: make(map[string]*memoryBucket)}
+}
+
+func (m *Memory) Take(_ context.Context, key string, bucket Bucket, n int, now time.Time) (bool, time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.evict(now)
+	state, ok := m.buckets[key]
+	if !ok {
+		state = &memoryBucket{tokens: float64(bucket.Burst), updated: now}
+		m.buckets[key] = state
+	}
+	if elapsed := now.Sub(state.updated); elapsed > 0 {
+		state.tokens = math.Min(float64(bucket.Burst), state.tokens+float64(elapsed)/float64(bucket.Interval))
+		state.updated = now
+	}
+
+	if state.tokens >= float64(n) {
+		state.tokens -= float64(n)
+		state.full = now.Add(time.Duration((float64(bucket.Burst) - state.tokens) * float64(bucket.Interval)))
+		return true, 0, nil
+	}
+	wait := time.Duration(math.Ceil((float64(n) - state.tokens) * float64(bucket.Interval)))
+	return false, wait, nil
+}
+
+// evict periodically drops buckets that have refilled.
+func (m *Memory) evict(now time.Time) {
+	if now.Sub(m.lastEvict) < evictInterval {
+		return
+	}
+	m.lastEvict = now
+	for key, state := range m.buckets {
+		if !now.Before(state.full) {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+func (m *Memory) Ping(context.Context) error {
+	return nil
+}
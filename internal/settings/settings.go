@@ -0,0 +1,151 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+
+	"music-bot-v2/internal/youtube"
+)
+
+// Version is the schema version of stored settings, bump it with a migration when a field changes meaning.
+const Version = 1
+
+// Format is how a track is delivered to Telegram.
+type Format string
+
+const (
+	// FormatAudio sends tracks as audio, playable in the Telegram player.
+	FormatAudio Format = "audio"
+	// FormatFile sends tracks as files, saved under their name as is.
+	FormatFile Format = "file"
+)
+
+// Values offered by the settings panel, in the order they are cycled through.
+var (
+	PageSizes = []int{5, 10, 20}
+	Formats   = []Format{FormatAudio, FormatFile}
+	Orders    = []string{youtube.OrderRelevance, youtube.OrderDate, youtube.OrderViewCount, youtube.OrderRating}
+)
+
+type cacherService interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+}
+
+// Settings are a user's preferences.
+type Settings struct {
+	Version  int    `json:"version"`
+	PageSize int    `json:"page_size"`
+	Format   Format `json:"format"`
+	// Order is the search ordering for queries without a sort operator.
+	Order string `json:"order"`
+	// Thumbnails attaches the video's thumbnail to delivered tracks as cover art.
+	Thumbnails bool `json:"thumbnails"`
+}
+
+// Default returns the settings of users who have not changed anything.
+func Default() Settings {
+	return Settings{
+		Version:    Version,
+		PageSize:   10,
+		Format:     FormatAudio,
+		Order:      youtube.OrderRelevance,
+		Thumbnails: true,
+	}
+}
+
+// migrations upgrade a stored document from the version they are keyed by to the next one.
+var migrations = map[int]func(s *Settings){}
+
+// normalize replaces values the panel does not offer, for instance ones removed since they were stored,
+// with the defaults.
+func (s *Settings) normalize() {
+	defaults := Default()
+	if !slices.Contains(PageSizes, s.PageSize) {
+		s.PageSize = defaults.PageSize
+	}
+	if !slices.Contains(Formats, s.Format) {
+		s.Format = defaults.Format
+	}
+	if !slices.Contains(Orders, s.Order) {
+		s.Order = defaults.Order
+	}
+}
+
+// Store keeps each user's settings; nothing is stored for users who never changed them.
+type Store struct {
+	cache cacherService
+	// mu keeps concurrent taps on the settings panel from undoing each other's change.
+	mu sync.Mutex
+}
+
+func NewStore(cache cacherService) *Store {
+	return &Store{cache: cache}
+}
+
+// Get returns the user's settings, the defaults when none are stored.
+func (s *Store) Get(ctx context.Context, user string) (Settings, error) {
+	if user == "" {
+		return Settings{}, errors.New("user is empty")
+	}
+
+	value, ok, err := s.cache.Get(ctx, user)
+	if err != nil {
+		return Settings{}, err
+	}
+	if !ok || value == "" {
+		return Default(), nil
+	}
+	return decode(value)
+}
+
+// Update applies fn to the user's settings and stores the result.
+func (s *Store) Update(ctx context.Context, user string, fn func(s *Settings)) (Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, err := s.Get(ctx, user)
+	if err != nil {
+		return Settings{}, err
+	}
+	fn(&settings)
+	settings.Version = Version
+	settings.normalize()
+
+	value, err := json.Marshal(settings)
+	if err != nil {
+		return Settings{}, err
+	}
+	if err := s.cache.Set(ctx, user, string(value)); err != nil {
+		return Settings{}, err
+	}
+	return settings, nil
+}
+
+// Reset returns the user to the default settings.
+func (s *Store) Reset(ctx context.Context, user string) (Settings, error) {
+	return s.Update(ctx, user, func(settings *Settings) {
+		*settings = Default()
+	})
+}
+
+// decode reads a stored document over the defaults, so fields added after it was written keep their default.
+// Older versions are migrated; a newer one, written by a newer release, is read as far as it is understood.
+func decode(value string) (Settings, error) {
+	settings := Default()
+	settings.Version = 0
+	if err := json.Unmarshal([]byte(value), &settings); err != nil {
+		return Settings{}, err
+	}
+	for settings.Version < Version {
+		if migrate, ok := migrations[settings.Version]; ok {
+			migrate(&settings)
+		}
+		settings.Version++
+	}
+	settings.normalize()
+	return settings, nil
+}
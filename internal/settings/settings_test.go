@@ -0,0 +1,124 @@
+package settings
+
+import (
+	"context"
+	"testing"
+
+	"music-bot-v2/internal/cacher"
+	"music-bot-v2/internal/youtube"
+)
+
+func testStore() *Store {
+	return NewStore(cacher.NewMemory(cacher.SettingsDB, 0, 0))
+}
+
+func TestGetReturnsDefaults(t *testing.T) {
+	got, err := testStore().Get(context.Background(), "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != Default() {
+		t.Fatalf("Get = %+v, want defaults %+v", got, Default())
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctx := context.Background()
+	store := testStore()
+
+	updated, err := store.Update(ctx, "user", func(s *Settings) {
+		s.PageSize = 5
+		s.Format = FormatFile
+		s.Order = youtube.OrderDate
+		s.Thumbnails = false
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := store.Get(ctx, "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != updated || got.PageSize != 5 || got.Format != FormatFile || got.Order != youtube.OrderDate || got.Thumbnails {
+		t.Fatalf("Get = %+v, Update returned %+v", got, updated)
+	}
+
+	if other, _ := store.Get(ctx, "other"); other != Default() {
+		t.Fatalf("settings leaked to another user: %+v", other)
+	}
+
+	if reset, err := store.Reset(ctx, "user"); err != nil || reset != Default() {
+		t.Fatalf("Reset = %+v, %v", reset, err)
+	}
+}
+
+func TestUpdateNormalizes(t *testing.T) {
+	got, err := testStore().Update(context.Background(), "user", func(s *Settings) {
+		s.PageSize = 7
+		s.Format = "flac"
+		s.Order = "random"
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != Default() {
+		t.Fatalf("Update = %+v, want defaults", got)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  Settings
+	}{
+		{
+			name:  "missing fields keep defaults",
+			value: `{"version":1,"page_size":20}`,
+			want:  Settings{Version: 1, PageSize: 20, Format: FormatAudio, Order: youtube.OrderRelevance, Thumbnails: true},
+		},
+		{
+			name:  "unversioned document",
+			value: `{"format":"file","thumbnails":false}`,
+			want:  Settings{Version: 1, PageSize: 10, Format: FormatFile, Order: youtube.OrderRelevance},
+		},
+		{
+			name:  "newer version with unknown fields",
+			value: `{"version":3,"page_size":5,"order":"viewCount","quality":"high"}`,
+			want:  Settings{Version: 3, PageSize: 5, Format: FormatAudio, Order: youtube.OrderViewCount, Thumbnails: true},
+		},
+		{
+			name:  "values no longer offered",
+			value: `{"version":1,"page_size":50,"order":"title"}`,
+			want:  Default(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decode(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Fatalf("decode = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := decode("{"); err == nil {
+		t.Fatal("decode accepted a broken document")
+	}
+}
+
+func TestDecodeMigrates(t *testing.T) {
+	migrations[0] = func(s *Settings) { s.PageSize *= 2 }
+	t.Cleanup(func() { delete(migrations, 0) })
+
+	got, err := decode(`{"page_size":10}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != Version || got.PageSize != 20 {
+		t.Fatalf("decode = %+v, want a migrated version %d document", got, Version)
+	}
+}
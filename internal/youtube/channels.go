@@ -0,0 +1,65 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"music-bot-v2/internal/application/transport"
+)
+
+const (
+	channelsEndpoint  = "/channels"
+	channelsQuotaCost = 1
+)
+
+// ErrChannelNotFound is returned when no channel has the requested handle.
+var ErrChannelNotFound = errors.New("channel not found")
+
+// ChannelIDByHandle resolves a channel handle (with or without the leading @) into a channel ID.
+func (c *Client) ChannelIDByHandle(ctx context.Context, handle string) (string, error) {
+	handle = strings.TrimSpace(handle)
+	if handle == "" {
+		return "", errors.New("channel handle is empty")
+	}
+
+	params := url.Values{}
+	params.Set("forHandle", handle)
+	params.Set("part", "id")
+
+	var payload channelsResponse
+	resp, apiErr, err := c.doWithKey(channelsQuotaCost, c.videosKey, func(key string) (transport.Response, *apiErrorPayload, error) {
+		params.Set("key", key)
+		request := transport.Request{
+			Method: http.MethodGet,
+			URL:    c.baseURL + channelsEndpoint,
+			Query:  params,
+		}
+
+		var resp transport.Response
+		var err error
+		resp, payload, err = transport.DoDecode(ctx, c.httpClient, request, transport.JSONDecoder[channelsResponse])
+		return resp, payload.Error, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return "", fmt.Errorf("channels failed: %s", formatAPIError(resp, apiErr))
+	}
+	if len(payload.Items) == 0 || payload.Items[0].ID == "" {
+		return "", ErrChannelNotFound
+	}
+
+	return payload.Items[0].ID, nil
+}
+
+type channelsResponse struct {
+	Items []struct {
+		ID string `json:"id"`
+	} `json:"items"`
+	Error *apiErrorPayload `json:"error"`
+}
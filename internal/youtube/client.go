@@ -1,8 +1,14 @@
 package youtube
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"music-bot-v2/internal/application/transport"
 )
@@ -20,9 +26,28 @@ type Client struct {
 	searchKeyMu   sync.Mutex
 	searchKeyNext int
 	baseURL       string
+	dailyQuota    int
+	quota         *quotaTracker
+	lastSuccess   atomic.Int64
+	lastFailure   atomic.Int64
 }
 
-func NewClient(apiKeys []string, httpClient *transport.Client) *Client {
+// maxFailingAge is how long the API may keep failing before the client is reported unhealthy.
+const maxFailingAge = 10 * time.Minute
+
+type Option func(*Client)
+
+// WithDailyQuota sets the daily quota units of every key, keys are skipped once the accounted usage reaches it.
+// Zero disables proactive skipping, keys are then skipped only after the API reports them exhausted.
+func WithDailyQuota(units int) Option {
+	return func(client *Client) {
+		if units >= 0 {
+			client.dailyQuota = units
+		}
+	}
+}
+
+func NewClient(apiKeys []string, httpClient *transport.Client, options ...Option) *Client {
 	if httpClient == nil {
 		httpClient = transport.New()
 	}
@@ -43,16 +68,74 @@ func NewClient(apiKeys []string, httpClient *transport.Client) *Client {
 		searchKeys = append(searchKeys, trimmedKeys[1:]...)
 	}
 
-	return &Client{
+	client := &Client{
 		httpClient:  httpClient,
 		videoAPIKey: videoKey,
 		searchKeys:  searchKeys,
 		baseURL:     apiV3BaseURL,
+		dailyQuota:  defaultDailyQuota,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	client.quota = newQuotaTracker(trimmedKeys, client.dailyQuota)
+
+	return client
+}
+
+// KeyUsage returns the accounted quota state of every configured key.
+func (c *Client) KeyUsage() []KeyUsage {
+	return c.quota.Usage()
+}
+
+// Ready reports the client unhealthy when API calls have kept failing for longer than maxFailingAge
+// or no key has quota left. An idle client is healthy. Neither state recovers without API calls, so the
+// check is meant to degrade readiness rather than fail it.
+func (c *Client) Ready(ctx context.Context) error {
+	// Keys are checked for the cheapest call, one that requests would still be sent with.
+	if !c.quota.anyAvailable(videosQuotaCost) {
+		return ErrNoAvailableKeys
+	}
+
+	lastFailure := c.lastFailure.Load()
+	lastSuccess := c.lastSuccess.Load()
+	if lastFailure == 0 || lastSuccess > lastFailure {
+		return nil
 	}
+	if lastSuccess == 0 {
+		return errors.New("no successful api calls yet, the last one failed")
+	}
+	age := time.Since(time.Unix(0, lastSuccess))
+	if age > maxFailingAge {
+		return fmt.Errorf("last successful api call %s ago", age.Round(time.Second))
+	}
+	return nil
 }
 
 type apiErrorPayload struct {
 	Message string `json:"message"`
+	Status  string `json:"status"`
+	Errors  []struct {
+		Reason string `json:"reason"`
+		Domain string `json:"domain"`
+	} `json:"errors"`
+	Details []struct {
+		Reason string `json:"reason"`
+	} `json:"details"`
+}
+
+func (p *apiErrorPayload) reasons() []string {
+	if p == nil {
+		return nil
+	}
+	reasons := make([]string, 0, len(p.Errors)+len(p.Details))
+	for _, e := range p.Errors {
+		reasons = append(reasons, e.Reason)
+	}
+	for _, d := range p.Details {
+		reasons = append(reasons, d.Reason)
+	}
+	return reasons
 }
 
 func formatAPIError(resp transport.Response, payload *apiErrorPayload) string {
@@ -68,23 +151,83 @@ func formatAPIError(resp transport.Response, payload *apiErrorPayload) string {
 	return resp.Status
 }
 
-func (c *Client) nextSearchKey() string {
+// doWithKey performs call with healthy keys picked by next, moving on to another key when the API rejects
+// the current one for quota or validity reasons. Other responses are returned as is for the caller to check.
+func (c *Client) doWithKey(
+	cost int,
+	next func(cost int, tried map[string]bool) string,
+	call func(key string) (transport.Response, *apiErrorPayload, error),
+) (transport.Response, *apiErrorPayload, error) {
+	tried := make(map[string]bool)
+	for {
+		key := next(cost, tried)
+		if key == "" {
+			c.lastFailure.Store(time.Now().UnixNano())
+			return transport.Response{}, nil, ErrNoAvailableKeys
+		}
+		tried[key] = true
+
+		resp, apiErr, err := call(key)
+		if err != nil {
+			c.lastFailure.Store(time.Now().UnixNano())
+			return resp, apiErr, err
+		}
+		if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusBadRequest {
+			switch kind, reason := classifyKeyError(apiErr); kind {
+			case keyErrorExhausted:
+				c.quota.markExhausted(key)
+				continue
+			case keyErrorInvalid:
+				c.quota.markDisabled(key, reason)
+				continue
+			}
+		}
+
+		c.quota.charge(key, cost)
+		if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+			c.lastSuccess.Store(time.Now().UnixNano())
+		} else {
+			c.lastFailure.Store(time.Now().UnixNano())
+		}
+		return resp, apiErr, nil
+	}
+}
+
+// nextSearchKey rotates search keys round-robin, skipping keys without quota; the video key is the fallback
+// when no dedicated search keys are configured.
+func (c *Client) nextSearchKey(cost int, tried map[string]bool) string {
 	c.searchKeyMu.Lock()
 	defer c.searchKeyMu.Unlock()
 	if len(c.searchKeys) == 0 {
-		return c.videoAPIKey
+		return c.usableKey(c.videoAPIKey, cost, tried)
 	}
-	key := c.searchKeys[c.searchKeyNext]
-	c.searchKeyNext++
-	if c.searchKeyNext >= len(c.searchKeys) {
-		c.searchKeyNext = 0
+	for range c.searchKeys {
+		key := c.searchKeys[c.searchKeyNext]
+		c.searchKeyNext++
+		if c.searchKeyNext >= len(c.searchKeys) {
+			c.searchKeyNext = 0
+		}
+		if c.usableKey(key, cost, tried) != "" {
+			return key
+		}
 	}
-	return key
+	return ""
+}
+
+// videosKey prefers the dedicated video key and borrows a search key while it is unavailable.
+func (c *Client) videosKey(cost int, tried map[string]bool) string {
+	if key := c.usableKey(c.videoAPIKey, cost, tried); key != "" {
+		return key
+	}
+	if len(c.searchKeys) == 0 {
+		return ""
+	}
+	return c.nextSearchKey(cost, tried)
 }
 
-func (c *Client) videosKey() string {
-	if c.videoAPIKey != "" {
-		return c.videoAPIKey
+func (c *Client) usableKey(key string, cost int, tried map[string]bool) string {
+	if key == "" || tried[key] || !c.quota.available(key, cost) {
+		return ""
 	}
-	return c.nextSearchKey()
+	return key
 }
@@ -0,0 +1,79 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"music-bot-v2/internal/application/transport"
+)
+
+const (
+	playlistItemsEndpoint   = "/playlistItems"
+	playlistItemsQuotaCost  = 1
+	playlistItemsMaxResults = 50
+)
+
+// ErrPlaylistNotFound is returned for playlists that do not exist or are private.
+var ErrPlaylistNotFound = errors.New("playlist not found")
+
+// PlaylistItems returns one page of video IDs of the playlist in playlist order and the token of the next page.
+func (c *Client) PlaylistItems(ctx context.Context, playlistID string, pageToken string) ([]string, string, error) {
+	if strings.TrimSpace(playlistID) == "" {
+		return nil, "", errors.New("playlist id is empty")
+	}
+
+	params := url.Values{}
+	params.Set("playlistId", playlistID)
+	params.Set("part", "contentDetails")
+	params.Set("maxResults", strconv.Itoa(playlistItemsMaxResults))
+	if strings.TrimSpace(pageToken) != "" {
+		params.Set("pageToken", pageToken)
+	}
+
+	var payload playlistItemsResponse
+	resp, apiErr, err := c.doWithKey(playlistItemsQuotaCost, c.videosKey, func(key string) (transport.Response, *apiErrorPayload, error) {
+		params.Set("key", key)
+		request := transport.Request{
+			Method: http.MethodGet,
+			URL:    c.baseURL + playlistItemsEndpoint,
+			Query:  params,
+		}
+
+		var resp transport.Response
+		var err error
+		resp, payload, err = transport.DoDecode(ctx, c.httpClient, request, transport.JSONDecoder[playlistItemsResponse])
+		return resp, payload.Error, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", ErrPlaylistNotFound
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, "", fmt.Errorf("playlist items failed: %s", formatAPIError(resp, apiErr))
+	}
+
+	ids := make([]string, 0, len(payload.Items))
+	for _, item := range payload.Items {
+		if item.ContentDetails.VideoID != "" {
+			ids = append(ids, item.ContentDetails.VideoID)
+		}
+	}
+	return ids, payload.NextPageToken, nil
+}
+
+type playlistItemsResponse struct {
+	NextPageToken string `json:"nextPageToken"`
+	Items         []struct {
+		ContentDetails struct {
+			VideoID string `json:"videoId"`
+		} `json:"contentDetails"`
+	} `json:"items"`
+	Error *apiErrorPayload `json:"error"`
+}
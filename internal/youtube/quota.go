@@ -0,0 +1,236 @@
+package youtube
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sync"
+	"time"
+	// Embedded so the Pacific-time quota reset works in images without system tzdata.
+	_ "time/tzdata"
+
+	"music-bot-v2/internal/application/metrics"
+)
+
+// Quota costs of the YouTube Data API v3 methods in use.
+const (
+	searchQuotaCost = 100
+	videosQuotaCost = 1
+
+	defaultDailyQuota = 10000
+)
+
+// ErrNoAvailableKeys is returned when every API key is out of quota or invalid.
+var ErrNoAvailableKeys = errors.New("no youtube api keys with available quota")
+
+var pacificLocation = mustLoadLocation("America/Los_Angeles")
+
+var (
+	quotaUsedUnits = metrics.NewGaugeVec(
+		"youtube_quota_used_units",
+		"YouTube Data API quota units accounted today per key.",
+		"key",
+	)
+	keyAvailable = metrics.NewGaugeVec(
+		"youtube_api_key_available",
+		"Whether the YouTube Data API key is currently used (1) or skipped (0).",
+		"key",
+	)
+)
+
+// KeyUsage describes the accounted quota state of an API key.
+type KeyUsage struct {
+	// Key is the masked API key, safe for logs and metrics.
+	Key            string
+	UsedUnits      int
+	ExhaustedUntil time.Time
+	Disabled       bool
+	DisabledReason string
+}
+
+// quotaTracker accounts quota units per key and remembers keys that are exhausted until the daily reset
+// (midnight Pacific time) or disabled for good.
+type quotaTracker struct {
+	mu         sync.Mutex
+	keys       map[string]*keyHealth
+	order      []string
+	dailyQuota int
+	now        func() time.Time
+}
+
+type keyHealth struct {
+	usedUnits      int
+	day            time.Time
+	exhaustedUntil time.Time
+	disabled       bool
+	disabledReason string
+}
+
+func newQuotaTracker(keys []string, dailyQuota int) *quotaTracker {
+	tracker := &quotaTracker{
+		keys:       make(map[string]*keyHealth, len(keys)),
+		dailyQuota: dailyQuota,
+		now:        time.Now,
+	}
+	for _, key := range keys {
+		if _, ok := tracker.keys[key]; ok {
+			continue
+		}
+		tracker.keys[key] = &keyHealth{}
+		tracker.order = append(tracker.order, key)
+		keyAvailable.Set(1, maskKey(key))
+	}
+	return tracker
+}
+
+// available reports whether the key is healthy and has room for cost units of today's quota.
+func (t *quotaTracker) available(key string, cost int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.hasRoom(key, cost)
+}
+
+// anyAvailable reports whether some key is available for cost units.
+func (t *quotaTracker) anyAvailable(cost int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, key := range t.order {
+		if t.hasRoom(key, cost) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRoom is available with t.mu held.
+func (t *quotaTracker) hasRoom(key string, cost int) bool {
+	health := t.health(key)
+	if health.disabled || t.now().Before(health.exhaustedUntil) {
+		return false
+	}
+	return t.dailyQuota <= 0 || health.usedUnits+cost <= t.dailyQuota
+}
+
+func (t *quotaTracker) charge(key string, cost int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	health := t.health(key)
+	health.usedUnits += cost
+	quotaUsedUnits.Set(float64(health.usedUnits), maskKey(key))
+}
+
+func (t *quotaTracker) markExhausted(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.health(key)
+	health.exhaustedUntil = nextQuotaReset(t.now())
+	keyAvailable.Set(0, maskKey(key))
+	log.Printf("youtube api key=%s quota exhausted until %s", maskKey(key), health.exhaustedUntil.Format(time.RFC3339))
+}
+
+func (t *quotaTracker) markDisabled(key string, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	health := t.health(key)
+	health.disabled = true
+	health.disabledReason = reason
+	keyAvailable.Set(0, maskKey(key))
+	log.Printf("youtube api key=%s disabled reason=%s", maskKey(key), reason)
+}
+
+// Usage returns the quota state of every key in configuration order.
+func (t *quotaTracker) Usage() []KeyUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := make([]KeyUsage, 0, len(t.order))
+	for _, key := range t.order {
+		health := t.health(key)
+		usage = append(usage, KeyUsage{
+			Key:            maskKey(key),
+			UsedUnits:      health.usedUnits,
+			ExhaustedUntil: health.exhaustedUntil,
+			Disabled:       health.disabled,
+			DisabledReason: health.disabledReason,
+		})
+	}
+	return usage
+}
+
+// health returns the key's state, rolling it over when a new quota day has started; t.mu must be held.
+func (t *quotaTracker) health(key string) *keyHealth {
+	health, ok := t.keys[key]
+	if !ok {
+		health = &keyHealth{}
+		t.keys[key] = health
+		t.order = append(t.order, key)
+	}
+
+	day := quotaDay(t.now())
+	if !health.day.Equal(day) {
+		health.day = day
+		health.usedUnits = 0
+		health.exhaustedUntil = time.Time{}
+		quotaUsedUnits.Set(0, maskKey(key))
+		if !health.disabled {
+			keyAvailable.Set(1, maskKey(key))
+		}
+	}
+	return health
+}
+
+// quotaDay returns the start of the quota day, quotas reset at midnight Pacific time.
+func quotaDay(now time.Time) time.Time {
+	pt := now.In(pacificLocation)
+	return time.Date(pt.Year(), pt.Month(), pt.Day(), 0, 0, 0, 0, pacificLocation)
+}
+
+func nextQuotaReset(now time.Time) time.Time {
+	day := quotaDay(now)
+	return time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, pacificLocation)
+}
+
+// keyErrorKind classifies API error reasons that are caused by the key rather than the request.
+type keyErrorKind int
+
+const (
+	keyErrorNone keyErrorKind = iota
+	keyErrorExhausted
+	keyErrorInvalid
+)
+
+func classifyKeyError(payload *apiErrorPayload) (keyErrorKind, string) {
+	for _, reason := range payload.reasons() {
+		switch reason {
+		case "quotaExceeded", "dailyLimitExceeded", "dailyLimitExceededUnreg":
+			return keyErrorExhausted, reason
+		case "keyInvalid", "keyExpired", "API_KEY_INVALID", "API_KEY_EXPIRED", "accessNotConfigured",
+			"SERVICE_DISABLED", "ipRefererBlocked", "API_KEY_SERVICE_BLOCKED":
+			return keyErrorInvalid, reason
+		}
+	}
+	return keyErrorNone, ""
+}
+
+// maskKey identifies a key in logs and metrics without revealing it: the last characters help to recognize the
+// key, the hash tells apart keys that end alike.
+func maskKey(key string) string {
+	const visible = 4
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:4])
+	if len(key) <= visible {
+		return "****-" + hash
+	}
+	return "****" + key[len(key)-visible:] + "-" + hash
+}
+
+func mustLoadLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		panic("load location " + name + ": " + err.Error())
+	}
+	return location
+}
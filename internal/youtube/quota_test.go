@@ -0,0 +1,105 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const quotaExceededBody = `{"error":{"code":403,"message":"quota","errors":[{"reason":"quotaExceeded","domain":"youtube.quota"}]}}`
+
+func TestSearchSkipsExhaustedKey(t *testing.T) {
+	var usedKeys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("key")
+		usedKeys = append(usedKeys, key)
+		if key == "search-a" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(quotaExceededBody))
+			return
+		}
+		_, _ = w.Write([]byte(`{"items":[{"id":{"kind":"youtube#video","videoId":"abc"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient([]string{"video", "search-a", "search-b"}, nil)
+	client.baseURL = server.URL
+
+	for i := 0; i < 2; i++ {
+		ids, _, err := client.Search(context.Background(), "query", SearchOptions{})
+		if err != nil {
+			t.Fatalf("Search error: %v", err)
+		}
+		if len(ids) != 1 || ids[0] != "abc" {
+			t.Fatalf("unexpected ids: %v", ids)
+		}
+	}
+
+	want := []string{"search-a", "search-b", "search-b"}
+	if len(usedKeys) != len(want) {
+		t.Fatalf("expected keys %v, got %v", want, usedKeys)
+	}
+	for i := range want {
+		if usedKeys[i] != want[i] {
+			t.Fatalf("expected keys %v, got %v", want, usedKeys)
+		}
+	}
+
+	usage := client.KeyUsage()
+	if usage[1].ExhaustedUntil.IsZero() || usage[2].UsedUnits != 2*searchQuotaCost {
+		t.Fatalf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestSearchFailsWhenDailyQuotaIsSpent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient([]string{"only"}, nil, WithDailyQuota(searchQuotaCost))
+	client.baseURL = server.URL
+
+	if _, _, err := client.Search(context.Background(), "query", SearchOptions{}); err != nil {
+		t.Fatalf("Search error: %v", err)
+	}
+	if _, _, err := client.Search(context.Background(), "query", SearchOptions{}); !errors.Is(err, ErrNoAvailableKeys) {
+		t.Fatalf("expected ErrNoAvailableKeys, got %v", err)
+	}
+	if err := client.Ready(context.Background()); !errors.Is(err, ErrNoAvailableKeys) {
+		t.Fatalf("Ready with the daily quota spent = %v, want ErrNoAvailableKeys", err)
+	}
+}
+
+func TestQuotaResetsAtPacificMidnight(t *testing.T) {
+	now := time.Date(2024, 3, 1, 7, 59, 0, 0, time.UTC) // 23:59 PST
+	tracker := newQuotaTracker([]string{"k"}, defaultDailyQuota)
+	tracker.now = func() time.Time { return now }
+
+	tracker.markExhausted("k")
+	if tracker.available("k", 1) {
+		t.Fatalf("expected exhausted key to be unavailable")
+	}
+
+	now = now.Add(time.Minute)
+	if !tracker.available("k", 1) {
+		t.Fatalf("expected key to be available after the Pacific midnight reset")
+	}
+}
+
+func TestMaskKeyTellsApartKeysWithTheSameEnding(t *testing.T) {
+	a, b := maskKey("AIzaFirst-abcd"), maskKey("AIzaOther-abcd")
+	if a == b {
+		t.Fatalf("keys with the same ending share the label %q", a)
+	}
+	if !strings.HasPrefix(a, "****abcd-") || strings.Contains(a, "First") {
+		t.Fatalf("maskKey = %q, want the ending and a hash only", a)
+	}
+	if a != maskKey("AIzaFirst-abcd") {
+		t.Fatal("maskKey is not stable")
+	}
+}
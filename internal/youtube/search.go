@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"music-bot-v2/internal/application/transport"
 )
@@ -15,40 +16,114 @@ import (
 const (
 	searchEndpoint   = "/search"
 	searchMaxResults = 10
+	// searchResultsLimit is the largest maxResults the API accepts.
+	searchResultsLimit = 50
+
+	// MusicCategoryID is the YouTube video category of music.
+	MusicCategoryID = "10"
+)
+
+// Values of SearchOptions.VideoDuration as defined by the API: short is under 4 minutes,
+// medium is 4 to 20 minutes and long is over 20 minutes.
+const (
+	VideoDurationAny    = ""
+	VideoDurationShort  = "short"
+	VideoDurationMedium = "medium"
+	VideoDurationLong   = "long"
+)
+
+// Values of SearchOptions.Order.
+const (
+	OrderRelevance = "relevance"
+	OrderDate      = "date"
+	OrderRating    = "rating"
+	OrderTitle     = "title"
+	OrderViewCount = "viewCount"
 )
 
+// SearchOptions are the optional search.list parameters, zero values are not sent.
+type SearchOptions struct {
+	PageToken       string
+	VideoDuration   string
+	Order           string
+	PublishedAfter  time.Time
+	PublishedBefore time.Time
+	ChannelID       string
+	CategoryID      string
+	// MaxResults is the page size, 10 when zero and at most 50.
+	MaxResults int
+}
+
+func (o SearchOptions) apply(params url.Values) {
+	if strings.TrimSpace(o.PageToken) != "" {
+		params.Set("pageToken", o.PageToken)
+	}
+	if o.VideoDuration != "" {
+		params.Set("videoDuration", o.VideoDuration)
+	}
+	if o.Order != "" {
+		params.Set("order", o.Order)
+	}
+	if !o.PublishedAfter.IsZero() {
+		params.Set("publishedAfter", o.PublishedAfter.UTC().Format(time.RFC3339))
+	}
+	if !o.PublishedBefore.IsZero() {
+		params.Set("publishedBefore", o.PublishedBefore.UTC().Format(time.RFC3339))
+	}
+	if o.ChannelID != "" {
+		params.Set("channelId", o.ChannelID)
+	}
+	if o.CategoryID != "" {
+		params.Set("videoCategoryId", o.CategoryID)
+	}
+}
+
+func (o SearchOptions) maxResults() int {
+	if o.MaxResults <= 0 {
+		return searchMaxResults
+	}
+	return min(o.MaxResults, searchResultsLimit)
+}
+
 type Pagination struct {
 	NextPageToken string
 	PrevPageToken string
 	TotalResults  int
 }
 
-func (c *Client) Search(ctx context.Context, query string, pageToken string) ([]string, Pagination, error) {
-	if strings.TrimSpace(query) == "" {
+// Search finds videos; the query may be empty only when the search is restricted to a channel.
+func (c *Client) Search(ctx context.Context, query string, opts SearchOptions) ([]string, Pagination, error) {
+	if strings.TrimSpace(query) == "" && opts.ChannelID == "" {
 		return nil, Pagination{}, errors.New("search query is empty")
 	}
 
 	params := url.Values{}
-	params.Set("key", c.nextSearchKey())
-	params.Set("q", query)
-	params.Set("type", "video")
-	params.Set("maxResults", strconv.Itoa(searchMaxResults))
-	if strings.TrimSpace(pageToken) != "" {
-		params.Set("pageToken", pageToken)
+	if strings.TrimSpace(query) != "" {
+		params.Set("q", query)
 	}
+	params.Set("type", "video")
+	params.Set("maxResults", strconv.Itoa(opts.maxResults()))
+	opts.apply(params)
 
-	request := transport.Request{
-		Method: http.MethodGet,
-		URL:    c.baseURL + searchEndpoint,
-		Query:  params,
-	}
+	var payload searchResponse
+	resp, apiErr, err := c.doWithKey(searchQuotaCost, c.nextSearchKey, func(key string) (transport.Response, *apiErrorPayload, error) {
+		params.Set("key", key)
+		request := transport.Request{
+			Method: http.MethodGet,
+			URL:    c.baseURL + searchEndpoint,
+			Query:  params,
+		}
 
-	resp, payload, err := transport.DoDecode(ctx, c.httpClient, request, transport.JSONDecoder[searchResponse])
+		var resp transport.Response
+		var err error
+		resp, payload, err = transport.DoDecode(ctx, c.httpClient, request, transport.JSONDecoder[searchResponse])
+		return resp, payload.Error, err
+	})
 	if err != nil {
 		return nil, Pagination{}, err
 	}
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, Pagination{}, fmt.Errorf("search failed: %s", formatAPIError(resp, payload.Error))
+		return nil, Pagination{}, fmt.Errorf("search failed: %s", formatAPIError(resp, apiErr))
 	}
 
 	ids := make([]string, 0, len(payload.Items))
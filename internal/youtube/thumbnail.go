@@ -0,0 +1,37 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"music-bot-v2/internal/application/transport"
+)
+
+// maxThumbnailBytes is Telegram's limit for audio thumbnails.
+const maxThumbnailBytes = 200 * 1024
+
+// Thumbnail downloads a video thumbnail image.
+func (c *Client) Thumbnail(ctx context.Context, url string) ([]byte, error) {
+	if strings.TrimSpace(url) == "" {
+		return nil, errors.New("thumbnail url is empty")
+	}
+
+	resp, err := c.httpClient.Do(ctx, transport.Request{
+		Method: http.MethodGet,
+		URL:    url,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("thumbnail failed: %s", resp.Status)
+	}
+	if len(resp.Body) == 0 || len(resp.Body) > maxThumbnailBytes {
+		return nil, fmt.Errorf("thumbnail size %d bytes is out of range", len(resp.Body))
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,96 @@
+package youtube
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var videoIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`)
+
+// videoPathPrefixes are the youtube.com paths followed by a video ID.
+var videoPathPrefixes = []string{"/shorts/", "/embed/", "/live/", "/v/", "/e/"}
+
+// WatchURL returns the canonical watch page link of a video.
+func WatchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + videoID
+}
+
+// ThumbnailURL returns the JPEG thumbnail every video has, whatever thumbnails the API lists.
+func ThumbnailURL(videoID string) string {
+	return "https://i.ytimg.com/vi/" + videoID + "/hqdefault.jpg"
+}
+
+// VideoIDFromURL extracts the video ID from a YouTube link: watch pages on youtube.com, m.youtube.com and
+// music.youtube.com, youtu.be short links, Shorts, embeds and live pages. The scheme is optional and
+// timestamps or other parameters are ignored.
+func VideoIDFromURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.ContainsAny(raw, " \t\n") {
+		return "", false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	var id string
+	switch host {
+	case "youtu.be":
+		id, _, _ = strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	case "youtube.com", "m.youtube.com", "music.youtube.com", "youtube-nocookie.com":
+		if u.Path == "/watch" {
+			id = u.Query().Get("v")
+			break
+		}
+		for _, prefix := range videoPathPrefixes {
+			if rest, ok := strings.CutPrefix(u.Path, prefix); ok {
+				id, _, _ = strings.Cut(rest, "/")
+				break
+			}
+		}
+	default:
+		return "", false
+	}
+
+	if !videoIDPattern.MatchString(id) {
+		return "", false
+	}
+	return id, true
+}
+
+var playlistIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{10,64}$`)
+
+// PlaylistIDFromURL extracts the list parameter from a YouTube playlist or watch link. Auto-generated mixes
+// (RD...) and the private Liked videos and Watch later lists are rejected, the API cannot list them; the
+// latter are the two-letter LL and WL, too short for playlistIDPattern.
+func PlaylistIDFromURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.ContainsAny(raw, " \t\n") {
+		return "", false
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	switch strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") {
+	case "youtu.be", "youtube.com", "m.youtube.com", "music.youtube.com":
+	default:
+		return "", false
+	}
+
+	id := u.Query().Get("list")
+	if !playlistIDPattern.MatchString(id) || strings.HasPrefix(id, "RD") {
+		return "", false
+	}
+	return id, true
+}
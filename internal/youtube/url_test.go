@@ -0,0 +1,57 @@
+package youtube
+
+import "testing"
+
+func TestVideoIDFromURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		wantID string
+		wantOK bool
+	}{
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "dQw4w9WgXcQ", true},
+		{"https://youtube.com/watch?feature=share&v=dQw4w9WgXcQ&t=42s", "dQw4w9WgXcQ", true},
+		{"https://m.youtube.com/watch?v=dQw4w9WgXcQ&list=PL123&index=2", "dQw4w9WgXcQ", true},
+		{"https://music.youtube.com/watch?v=dQw4w9WgXcQ&si=abc", "dQw4w9WgXcQ", true},
+		{"https://youtu.be/dQw4w9WgXcQ?t=1m30s", "dQw4w9WgXcQ", true},
+		{"youtu.be/dQw4w9WgXcQ", "dQw4w9WgXcQ", true},
+		{"https://www.youtube.com/shorts/dQw4w9WgXcQ?feature=share", "dQw4w9WgXcQ", true},
+		{"https://www.youtube.com/embed/dQw4w9WgXcQ?start=30", "dQw4w9WgXcQ", true},
+		{"https://www.youtube.com/live/dQw4w9WgXcQ", "dQw4w9WgXcQ", true},
+		{"http://WWW.YOUTUBE.COM/watch?v=dQw4w9WgXcQ#t=10", "dQw4w9WgXcQ", true},
+		{"https://www.youtube.com/playlist?list=PL123", "", false},
+		{"https://www.youtube.com/watch?v=short", "", false},
+		{"https://example.com/watch?v=dQw4w9WgXcQ", "", false},
+		{"never gonna give you up", "", false},
+	}
+
+	for _, tt := range tests {
+		id, ok := VideoIDFromURL(tt.url)
+		if id != tt.wantID || ok != tt.wantOK {
+			t.Errorf("VideoIDFromURL(%q) = %q, %v; want %q, %v", tt.url, id, ok, tt.wantID, tt.wantOK)
+		}
+	}
+}
+
+func TestPlaylistIDFromURL(t *testing.T) {
+	tests := []struct {
+		url    string
+		wantID string
+		wantOK bool
+	}{
+		{"https://www.youtube.com/playlist?list=PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI", "PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI", true},
+		{"https://music.youtube.com/playlist?list=OLAK5uy_k8qvKSXQ4k2UJ0L4sGZ2UqK3xM1tFq8Tk", "OLAK5uy_k8qvKSXQ4k2UJ0L4sGZ2UqK3xM1tFq8Tk", true},
+		{"https://youtube.com/watch?v=dQw4w9WgXcQ&list=PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI&index=3", "PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI", true},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ&list=RDdQw4w9WgXcQ", "", false},
+		{"https://www.youtube.com/playlist?list=WL", "", false},
+		{"https://www.youtube.com/playlist?list=LL", "", false},
+		{"https://www.youtube.com/watch?v=dQw4w9WgXcQ", "", false},
+		{"https://example.com/playlist?list=PLFgquLnL59alCl_2TQvOiD5Vgm1hCaGSI", "", false},
+	}
+
+	for _, tt := range tests {
+		id, ok := PlaylistIDFromURL(tt.url)
+		if id != tt.wantID || ok != tt.wantOK {
+			t.Errorf("PlaylistIDFromURL(%q) = %q, %v; want %q, %v", tt.url, id, ok, tt.wantID, tt.wantOK)
+		}
+	}
+}
@@ -9,46 +9,75 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"music-bot-v2/internal/application/transport"
 )
 
-var isoDurationRE = regexp.MustCompile(`^PT(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?$`)
+var isoDurationRE = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
 
-func (c *Client) Videos(ctx context.Context, ids []string) (map[string]string, error) {
+// Video is the metadata of a YouTube video.
+type Video struct {
+	ID           string
+	Title        string
+	ChannelTitle string
+	Duration     time.Duration
+	ThumbnailURL string
+	PublishedAt  time.Time
+	// LiveBroadcastContent is "live" or "upcoming" for broadcasts and "none" for regular videos.
+	LiveBroadcastContent string
+}
+
+func (c *Client) Videos(ctx context.Context, ids []string) (map[string]Video, error) {
 	if len(ids) == 0 {
 		return nil, errors.New("video ids are empty")
 	}
 
 	params := url.Values{}
-	params.Set("key", c.videosKey())
 	params.Set("id", strings.Join(ids, ","))
 	params.Set("part", "snippet,contentDetails")
 
-	request := transport.Request{
-		Method: http.MethodGet,
-		URL:    c.baseURL + videoEndpoint,
-		Query:  params,
-	}
+	var payload videosResponse
+	resp, apiErr, err := c.doWithKey(videosQuotaCost, c.videosKey, func(key string) (transport.Response, *apiErrorPayload, error) {
+		params.Set("key", key)
+		request := transport.Request{
+			Method: http.MethodGet,
+			URL:    c.baseURL + videoEndpoint,
+			Query:  params,
+		}
 
-	resp, payload, err := transport.DoDecode(ctx, c.httpClient, request, transport.JSONDecoder[videosResponse])
+		var resp transport.Response
+		var err error
+		resp, payload, err = transport.DoDecode(ctx, c.httpClient, request, transport.JSONDecoder[videosResponse])
+		return resp, payload.Error, err
+	})
 	if err != nil {
 		return nil, err
 	}
 	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
-		return nil, fmt.Errorf("videos failed: %s", formatAPIError(resp, payload.Error))
+		return nil, fmt.Errorf("videos failed: %s", formatAPIError(resp, apiErr))
 	}
 
-	results := make(map[string]string, len(payload.Items))
+	results := make(map[string]Video, len(payload.Items))
 	for _, item := range payload.Items {
 		if item.Kind != "youtube#video" {
 			continue
 		}
-		formatted, err := formatDuration(item.ContentDetails.Duration)
+		duration, err := parseDuration(item.ContentDetails.Duration)
 		if err != nil {
 			return nil, err
 		}
-		results[item.ID] = fmt.Sprintf("%s %s", formatted, item.Snippet.Title)
+		publishedAt, _ := time.Parse(time.RFC3339, item.Snippet.PublishedAt)
+		results[item.ID] = Video{
+			ID:           item.ID,
+			Title:        item.Snippet.Title,
+			ChannelTitle: item.Snippet.ChannelTitle,
+			Duration:     duration,
+			ThumbnailURL: item.Snippet.Thumbnails.url(),
+			PublishedAt:  publishedAt,
+
+			LiveBroadcastContent: item.Snippet.LiveBroadcastContent,
+		}
 	}
 
 	return results, nil
@@ -59,7 +88,12 @@ type videosResponse struct {
 		Kind    string `json:"kind"`
 		ID      string `json:"id"`
 		Snippet struct {
-			Title string `json:"title"`
+			Title        string     `json:"title"`
+			ChannelTitle string     `json:"channelTitle"`
+			PublishedAt  string     `json:"publishedAt"`
+			Thumbnails   thumbnails `json:"thumbnails"`
+
+			LiveBroadcastContent string `json:"liveBroadcastContent"`
 		} `json:"snippet"`
 		ContentDetails struct {
 			Duration string `json:"duration"`
@@ -68,33 +102,46 @@ type videosResponse struct {
 	Error *apiErrorPayload `json:"error"`
 }
 
-func formatDuration(raw string) (string, error) {
-	if strings.TrimSpace(raw) == "" {
-		return "", errors.New("duration is empty")
-	}
+type thumbnail struct {
+	URL string `json:"url"`
+}
 
-	matches := isoDurationRE.FindStringSubmatch(raw)
-	if matches == nil {
-		return "", fmt.Errorf("invalid duration format: %s", raw)
-	}
+type thumbnails struct {
+	Default *thumbnail `json:"default"`
+	Medium  *thumbnail `json:"medium"`
+	High    *thumbnail `json:"high"`
+}
 
-	hours, err := parseDurationPart(matches[1])
-	if err != nil {
-		return "", err
+// url prefers the medium thumbnail (320x180), the largest one Telegram accepts as an audio thumbnail.
+func (t thumbnails) url() string {
+	for _, candidate := range []*thumbnail{t.Medium, t.Default, t.High} {
+		if candidate != nil && candidate.URL != "" {
+			return candidate.URL
+		}
 	}
-	minutes, err := parseDurationPart(matches[2])
-	if err != nil {
-		return "", err
+	return ""
+}
+
+// parseDuration parses an ISO 8601 duration as returned in contentDetails, e.g. PT4M13S, P1DT2H or P0D for live streams.
+func parseDuration(raw string) (time.Duration, error) {
+	if strings.TrimSpace(raw) == "" {
+		return 0, errors.New("duration is empty")
 	}
-	seconds, err := parseDurationPart(matches[3])
-	if err != nil {
-		return "", err
+	matches := isoDurationRE.FindStringSubmatch(raw)
+	if matches == nil {
+		return 0, fmt.Errorf("invalid duration format: %s", raw)
 	}
 
-	if hours > 0 {
-		return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds), nil
+	var total time.Duration
+	units := []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second}
+	for i, unit := range units {
+		value, err := parseDurationPart(matches[i+1])
+		if err != nil {
+			return 0, err
+		}
+		total += time.Duration(value) * unit
 	}
-	return fmt.Sprintf("%02d:%02d", minutes, seconds), nil
+	return total, nil
 }
 
 func parseDurationPart(raw string) (int, error) {